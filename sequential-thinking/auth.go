@@ -0,0 +1,69 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// parseAuthTokens splits the comma-separated list of bearer tokens, dropping empty entries.
+func parseAuthTokens(s string) []string {
+	var tokens []string
+	for token := range strings.SplitSeq(s, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// requireBearerToken wraps next so that only requests with an "Authorization: Bearer <token>" header
+// carrying one of tokens are served. Other requests get a 401 with a WWW-Authenticate challenge.
+//
+// Several tokens may be accepted at once so that a token can be rotated without downtime.
+func requireBearerToken(tokens []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(tokens, bearerToken(r)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sequential-thinking"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token of the "Authorization: Bearer <token>" header of r, or "" if there is none.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// validToken reports whether token is one of tokens.
+//
+// Every token is compared in constant time, so the timing doesn't reveal which token, or how much of it, matched.
+func validToken(tokens []string, token string) bool {
+	valid := 0
+	for _, t := range tokens {
+		valid |= subtle.ConstantTimeCompare([]byte(t), []byte(token))
+	}
+	return valid == 1
+}
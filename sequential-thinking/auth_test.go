@@ -0,0 +1,128 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestParseAuthTokens(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want []string
+	}{
+		"Empty":      {in: "", want: nil},
+		"Blank":      {in: " , ,\t", want: nil},
+		"Single":     {in: "secret", want: []string{"secret"}},
+		"Rotated":    {in: "old,new", want: []string{"old", "new"}},
+		"Whitespace": {in: " old , ,new\t,", want: []string{"old", "new"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := parseAuthTokens(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("parseAuthTokens(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	// A blank entry must not let a request without a token through
+	tokens := parseAuthTokens("old-token, ,new-token,")
+	handler := requireBearerToken(tokens, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := map[string]struct {
+		authorization string
+		wantCode      int
+	}{
+		"MissingHeader":   {authorization: "", wantCode: http.StatusUnauthorized},
+		"EmptyToken":      {authorization: "Bearer ", wantCode: http.StatusUnauthorized},
+		"BasicScheme":     {authorization: "Basic old-token", wantCode: http.StatusUnauthorized},
+		"NoScheme":        {authorization: "old-token", wantCode: http.StatusUnauthorized},
+		"WrongToken":      {authorization: "Bearer other-token", wantCode: http.StatusUnauthorized},
+		"TokenPrefix":     {authorization: "Bearer old", wantCode: http.StatusUnauthorized},
+		"OldToken":        {authorization: "Bearer old-token", wantCode: http.StatusNoContent},
+		"NewToken":        {authorization: "Bearer new-token", wantCode: http.StatusNoContent},
+		"LowercaseScheme": {authorization: "bearer new-token", wantCode: http.StatusNoContent},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if tt.wantCode == http.StatusUnauthorized && challenge != `Bearer realm="sequential-thinking"` {
+				t.Errorf("WWW-Authenticate = %q, want a Bearer challenge", challenge)
+			}
+			if tt.wantCode != http.StatusUnauthorized && challenge != "" {
+				t.Errorf("WWW-Authenticate = %q on an authorized request", challenge)
+			}
+		})
+	}
+}
+
+func TestOperationalEndpointsSkipAuth(t *testing.T) {
+	s := newTestServer(t, nil)
+	started := time.Now()
+	reg := prometheus.NewRegistry()
+	if err := registerMetrics(reg, s.counts); err != nil {
+		t.Fatalf("registerMetrics: %v", err)
+	}
+	mcpHandler := requireBearerToken([]string{"secret"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux := newHTTPMux(mcpHandler, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}), s.HealthHandler(started), s.ReadyHandler(started))
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		wantCode int
+	}{
+		"Metrics":       {method: http.MethodGet, path: "/metrics", wantCode: http.StatusOK},
+		"Healthz":       {method: http.MethodGet, path: "/healthz", wantCode: http.StatusOK},
+		"Readyz":        {method: http.MethodGet, path: "/readyz", wantCode: http.StatusOK},
+		"MCPEndpoint":   {method: http.MethodPost, path: "/", wantCode: http.StatusUnauthorized},
+		"MCPStream":     {method: http.MethodGet, path: "/", wantCode: http.StatusUnauthorized},
+		"OtherPath":     {method: http.MethodGet, path: "/healthz/extra", wantCode: http.StatusUnauthorized},
+		"PostToHealthz": {method: http.MethodPost, path: "/healthz", wantCode: http.StatusUnauthorized},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("%s %s without a token: status = %d, want %d", tt.method, tt.path, rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bytedance/gg/gson"
+	"github.com/bytedance/sonic"
+)
+
+// healthStatus is the JSON body of the /healthz and /readyz endpoints.
+type healthStatus struct {
+	Status   string `json:"status"`
+	Uptime   string `json:"uptime"`
+	Thoughts int    `json:"thoughts"`
+	Branches int    `json:"branches"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HealthHandler returns the handler of the /healthz liveness endpoint, which always reports ok once the listener is up.
+func (s *SequentialThinkingServer) HealthHandler(started time.Time) http.Handler {
+	return healthHandler(started, s.counts, nil)
+}
+
+// ReadyHandler returns the handler of the /readyz readiness endpoint.
+//
+// When the state is persisted to a state directory, the server is ready only while the directory is reachable.
+func (s *SequentialThinkingServer) ReadyHandler(started time.Time) http.Handler {
+	return healthHandler(started, s.counts, s.checkReady)
+}
+
+// counts returns the number of recorded thoughts and branches.
+func (s *SequentialThinkingServer) counts() (thoughts, branches int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.thoughtHistory), len(s.branches)
+}
+
+// checkReady checks that the persistence backend, if any, is reachable.
+func (s *SequentialThinkingServer) checkReady() error {
+	if s.stateDir == "" {
+		return nil
+	}
+	fi, err := os.Stat(s.stateDir)
+	if err != nil {
+		return fmt.Errorf("state directory: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("state directory %s is not a directory", s.stateDir)
+	}
+	return nil
+}
+
+// healthHandler returns a handler writing the health status with the thought and branch counts returned by counts.
+//
+// If check is not nil and fails, the status is 503 Service Unavailable.
+func healthHandler(started time.Time, counts func() (thoughts, branches int), check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := healthStatus{
+			Status: "ok",
+			Uptime: time.Since(started).Round(time.Second).String(),
+		}
+		status.Thoughts, status.Branches = counts()
+
+		code := http.StatusOK
+		if check != nil {
+			if err := check(); err != nil {
+				code = http.StatusServiceUnavailable
+				status.Status = "unavailable"
+				status.Error = err.Error()
+			}
+		}
+
+		data, err := gson.MarshalBy(sonic.ConfigStd, status)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if _, err := w.Write(data); err != nil {
+			slog.DebugContext(r.Context(), "write health status", slog.Any("error", err))
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// getHealth serves a GET request with h and returns the status code and the decoded health status.
+func getHealth(t *testing.T, h http.Handler, path string) (int, healthStatus) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("%s Content-Type = %q, want application/json", path, got)
+	}
+	var status healthStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("unmarshal %s response %q: %v", path, rec.Body.String(), err)
+	}
+
+	return rec.Code, status
+}
+
+func TestHealthHandler(t *testing.T) {
+	s := newTestServer(t, nil)
+	thoughts := []ThoughtData{
+		{Thought: "first", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 2},
+		{Thought: "second", NextThoughtNeeded: false, ThoughtNumber: 2, TotalThoughts: 2},
+		{Thought: "alternative", NextThoughtNeeded: false, ThoughtNumber: 2, TotalThoughts: 2, BranchFromThought: 1, BranchId: "alt"},
+	}
+	for _, thought := range thoughts {
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			t.Fatalf("ProcessThought(%q): %v", thought.Thought, err)
+		}
+	}
+
+	code, status := getHealth(t, s.HealthHandler(time.Now()), "/healthz")
+	if code != http.StatusOK {
+		t.Errorf("status code = %d, want %d", code, http.StatusOK)
+	}
+	if status.Uptime == "" {
+		t.Error("uptime is empty")
+	}
+	status.Uptime = ""
+	want := healthStatus{Status: "ok", Thoughts: 3, Branches: 1}
+	if status != want {
+		t.Errorf("health status = %+v, want %+v", status, want)
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	t.Run("NoStateDir", func(t *testing.T) {
+		s := newTestServer(t, nil)
+
+		code, status := getHealth(t, s.ReadyHandler(time.Now()), "/readyz")
+		if code != http.StatusOK || status.Status != "ok" || status.Error != "" {
+			t.Errorf("got %d %+v, want %d with status ok", code, status, http.StatusOK)
+		}
+	})
+
+	t.Run("StateDir", func(t *testing.T) {
+		s := newTestServer(t, &Options{StateDir: t.TempDir()})
+
+		code, status := getHealth(t, s.ReadyHandler(time.Now()), "/readyz")
+		if code != http.StatusOK || status.Status != "ok" || status.Error != "" {
+			t.Errorf("got %d %+v, want %d with status ok", code, status, http.StatusOK)
+		}
+	})
+
+	t.Run("StateDirGone", func(t *testing.T) {
+		stateDir := filepath.Join(t.TempDir(), "state")
+		s := newTestServer(t, &Options{StateDir: stateDir})
+		if err := os.RemoveAll(stateDir); err != nil {
+			t.Fatal(err)
+		}
+
+		code, status := getHealth(t, s.ReadyHandler(time.Now()), "/readyz")
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("status code = %d, want %d", code, http.StatusServiceUnavailable)
+		}
+		if status.Status != "unavailable" || status.Error == "" {
+			t.Errorf("health status = %+v, want status unavailable with an error", status)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientStates tracks the thinking state of each connected client when the clients are isolated with -isolate-clients.
+//
+// It backs the health and metrics counts, which sum the states of all clients, and the admin view across all clients.
+type clientStates struct {
+	mu      sync.Mutex
+	clients map[*SequentialThinkingServer]string // thinking state to the MCP session ID of its client
+	admins  map[string]bool                      // MCP session IDs of the admin clients
+}
+
+// newClientStates returns an empty client state registry.
+func newClientStates() *clientStates {
+	return &clientStates{
+		clients: make(map[*SequentialThinkingServer]string),
+		admins:  make(map[string]bool),
+	}
+}
+
+// untilSessionEnds returns a receiving middleware that calls register with each session on its first request,
+// then unregister once that session ends.
+func untilSessionEnds(register, unregister func(ss *mcp.ServerSession)) mcp.Middleware {
+	var (
+		mu       sync.Mutex
+		sessions = make(map[*mcp.ServerSession]bool)
+	)
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if ss, ok := req.GetSession().(*mcp.ServerSession); ok {
+				mu.Lock()
+				seen := sessions[ss]
+				sessions[ss] = true
+				mu.Unlock()
+
+				if !seen {
+					register(ss)
+					go func() {
+						if err := ss.Wait(); err != nil {
+							slog.Debug("client session ended", slog.String("session", ss.ID()), slog.Any("error", err))
+						}
+						unregister(ss)
+						mu.Lock()
+						delete(sessions, ss)
+						mu.Unlock()
+					}()
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// track returns a receiving middleware that registers st, the thinking state of a single client, until the client session ends.
+func (c *clientStates) track(st *SequentialThinkingServer) mcp.Middleware {
+	return untilSessionEnds(func(ss *mcp.ServerSession) {
+		c.mu.Lock()
+		c.clients[st] = ss.ID()
+		c.mu.Unlock()
+	}, func(*mcp.ServerSession) {
+		c.mu.Lock()
+		delete(c.clients, st)
+		c.mu.Unlock()
+	})
+}
+
+// counts returns the number of recorded thoughts and branches summed over the connected clients.
+func (c *clientStates) counts() (thoughts, branches int) {
+	for st := range c.snapshot() {
+		t, b := st.counts()
+		thoughts += t
+		branches += b
+	}
+	return thoughts, branches
+}
+
+// snapshot returns a copy of the registered thinking states and their session IDs.
+func (c *clientStates) snapshot() map[*SequentialThinkingServer]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return maps.Clone(c.clients)
+}
+
+// SearchThoughts searches the recorded thoughts of every connected client for args.Query.
+//
+// The matches are ordered by session ID, then history order, and carry the session ID of the client that recorded them.
+func (c *clientStates) SearchThoughts(ctx context.Context, request *mcp.CallToolRequest, args SearchThoughtsArgs) (*mcp.CallToolResult, *SearchThoughtsResult, error) {
+	re, limit, err := compileSearch(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clients := c.snapshot()
+	states := slices.SortedFunc(maps.Keys(clients), func(a, b *SequentialThinkingServer) int {
+		return strings.Compare(clients[a], clients[b])
+	})
+	result := &SearchThoughtsResult{
+		Matches: make([]ThoughtMatch, 0),
+	}
+	for _, st := range states {
+		result.Matches = st.search(result.Matches, re, args.BranchID, limit, clients[st])
+	}
+
+	return searchResult(args.Query, result), result, nil
+}
+
+// newAdminMCPServer creates the MCP server of the admin clients of isolated clients.
+//
+// It only serves a read-only search_thoughts across the thoughts of every connected client.
+// The sessions it creates are registered as admin sessions until they end.
+func (c *clientStates) newAdminMCPServer(logger *slog.Logger) (*mcp.Server, error) {
+	srv := mcp.NewServer(&mcp.Implementation{
+		Name:    "sequential-thinking-admin",
+		Version: version,
+	}, &mcp.ServerOptions{
+		Logger:   logger,
+		HasTools: true,
+		GetSessionID: func() string {
+			// Registered before the session ID is handed out, so no request can use it before it is bound to the admin tokens
+			sessionID := uuid.NewString()
+			c.mu.Lock()
+			c.admins[sessionID] = true
+			c.mu.Unlock()
+			return sessionID
+		},
+	})
+
+	schema, err := searchThoughtsInputSchema()
+	if err != nil {
+		return nil, err
+	}
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "search_thoughts",
+		Description: "Search the recorded thoughts of every connected client, including branch thoughts, for a case-insensitive substring or regular expression",
+		InputSchema: schema,
+	}, c.SearchThoughts)
+	srv.AddReceivingMiddleware(traceToolCalls, untilSessionEnds(func(*mcp.ServerSession) {}, func(ss *mcp.ServerSession) {
+		c.mu.Lock()
+		delete(c.admins, ss.ID())
+		c.mu.Unlock()
+	}))
+
+	return srv, nil
+}
+
+// isAdminSession reports whether sessionID is the MCP session ID of an admin client.
+func (c *clientStates) isAdminSession(sessionID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.admins[sessionID]
+}
+
+// servers returns the function choosing the MCP server of each new client session of the streamable HTTP handler.
+//
+// A client presenting one of adminTokens gets the admin server, any other client a server with its own thinking state
+// configured by opts.
+func (c *clientStates) servers(logger *slog.Logger, opts *Options, adminTokens []string) (func(*http.Request) *mcp.Server, error) {
+	adminSrv, err := c.newAdminMCPServer(logger)
+	if err != nil {
+		return nil, err
+	}
+	adminSrv.AddReceivingMiddleware(instrumentToolCalls)
+
+	return func(r *http.Request) *mcp.Server {
+		if len(adminTokens) > 0 && validToken(adminTokens, bearerToken(r)) {
+			return adminSrv
+		}
+		st, err := NewSequentialThinkingServer(opts)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "create client sequential thinking server", slog.Any("error", err))
+			return nil
+		}
+		clientSrv, err := newMCPServer(logger, st)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "create client mcp server", slog.Any("error", err))
+			return nil
+		}
+		clientSrv.AddReceivingMiddleware(instrumentToolCalls, c.track(st))
+		return clientSrv
+	}, nil
+}
+
+// requireAdminSessionToken wraps next so that the requests to an admin session must carry one of adminTokens too.
+//
+// The streamable HTTP handler only picks the server of a session when it is initialized, and routes the later
+// requests by their session ID alone. Without this, a client holding any token that got hold of an admin session ID
+// could use the admin server.
+func (c *clientStates) requireAdminSessionToken(adminTokens []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionID := r.Header.Get("Mcp-Session-Id"); sessionID != "" && c.isAdminSession(sessionID) && !validToken(adminTokens, bearerToken(r)) {
+			http.Error(w, "forbidden: admin session", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
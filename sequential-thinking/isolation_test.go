@@ -0,0 +1,223 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectClient connects a new client to srv over in-memory transports.
+func connectClient(t *testing.T, srv *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.Connect(t.Context(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	t.Cleanup(func() { ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	cs, err := client.Connect(t.Context(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+
+	return cs
+}
+
+func TestClientStates(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	clients := newClientStates()
+
+	sessions := make([]*mcp.ClientSession, 2)
+	for i := range sessions {
+		st := newTestServer(t, &Options{})
+		srv, err := newMCPServer(logger, st)
+		if err != nil {
+			t.Fatalf("newMCPServer: %v", err)
+		}
+		srv.AddReceivingMiddleware(clients.track(st))
+		sessions[i] = connectClient(t, srv)
+
+		res, err := sessions[i].CallTool(t.Context(), &mcp.CallToolParams{
+			Name: "sequentialthinking",
+			Arguments: map[string]any{
+				"thought":           fmt.Sprintf("client %d needle", i),
+				"nextThoughtNeeded": false,
+				"thoughtNumber":     1,
+				"totalThoughts":     1,
+			},
+		})
+		if err != nil || res.IsError {
+			t.Fatalf("client %d sequentialthinking: %v %v", i, err, res)
+		}
+	}
+
+	if thoughts, branches := clients.counts(); thoughts != 2 || branches != 0 {
+		t.Errorf("counts() = %d, %d, want 2, 0", thoughts, branches)
+	}
+
+	// A client is dropped from the aggregate once its session ends
+	sessions[0].Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		thoughts, _ := clients.counts()
+		if thoughts == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("counts() after closing a session = %d thoughts, want 1", thoughts)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// bearerTransport adds an "Authorization: Bearer <token>" header to each request.
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// connectHTTPClient connects a new client presenting token to the streamable HTTP endpoint at url.
+func connectHTTPClient(t *testing.T, url, token string) *mcp.ClientSession {
+	t.Helper()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	cs, err := client.Connect(t.Context(), &mcp.StreamableClientTransport{
+		Endpoint:   url,
+		HTTPClient: &http.Client{Transport: &bearerTransport{token: token}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+
+	return cs
+}
+
+func TestAdminSessions(t *testing.T) {
+	t.Setenv("DISABLE_THOUGHT_LOGGING", "true")
+
+	const (
+		userToken  = "user-token"
+		adminToken = "admin-token"
+	)
+	clients := newClientStates()
+	servers, err := clients.servers(slog.New(slog.DiscardHandler), &Options{}, []string{adminToken})
+	if err != nil {
+		t.Fatalf("servers: %v", err)
+	}
+	handler := clients.requireAdminSessionToken([]string{adminToken}, mcp.NewStreamableHTTPHandler(servers, nil))
+	ts := httptest.NewServer(requireBearerToken([]string{userToken, adminToken}, handler))
+	t.Cleanup(ts.Close)
+
+	for i := range 2 {
+		cs := connectHTTPClient(t, ts.URL, userToken)
+		res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+			Name: "sequentialthinking",
+			Arguments: map[string]any{
+				"thought":           fmt.Sprintf("client %d needle", i),
+				"nextThoughtNeeded": false,
+				"thoughtNumber":     1,
+				"totalThoughts":     1,
+			},
+		})
+		if err != nil || res.IsError {
+			t.Fatalf("client %d sequentialthinking: %v %v", i, err, res)
+		}
+
+		// A client only sees its own thoughts
+		res, err = cs.CallTool(t.Context(), &mcp.CallToolParams{
+			Name:      "search_thoughts",
+			Arguments: map[string]any{"query": "needle"},
+		})
+		if err != nil || res.IsError {
+			t.Fatalf("client %d search_thoughts: %v %v", i, err, res)
+		}
+		if got := searchMatches(t, res); len(got) != 1 {
+			t.Errorf("client %d search_thoughts matches = %d, want 1: %+v", i, len(got), got)
+		}
+	}
+
+	admin := connectHTTPClient(t, ts.URL, adminToken)
+	res, err := admin.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "search_thoughts",
+		Arguments: map[string]any{"query": "needle"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("admin search_thoughts: %v %v", err, res)
+	}
+	matches := searchMatches(t, res)
+	if len(matches) != 2 {
+		t.Fatalf("admin search_thoughts matches = %d, want 2: %+v", len(matches), matches)
+	}
+	for _, match := range matches {
+		if match.SessionID == "" {
+			t.Errorf("admin search_thoughts match %+v has no session ID", match)
+		}
+	}
+
+	// The admin session stays bound to the admin tokens after its initialization
+	body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_thoughts","arguments":{"query":"needle"}}}`
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, ts.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Mcp-Session-Id", admin.ID())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST with a user token to the admin session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("POST with a user token to the admin session: got status %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
+
+// searchMatches decodes the matches of a search_thoughts result.
+func searchMatches(t *testing.T, res *mcp.CallToolResult) []ThoughtMatch {
+	t.Helper()
+
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatalf("marshal search_thoughts result: %v", err)
+	}
+	var result SearchThoughtsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("unmarshal search_thoughts result: %v", err)
+	}
+	return result.Matches
+}
@@ -37,12 +37,15 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/bytedance/gg/gson"
 	"github.com/bytedance/sonic"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/google/uuid"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // description is the description of the sequential thinking tool.
@@ -86,7 +89,12 @@ Parameters explained:
 * revisesThought (integer): If is_revision is true, which thought number is being reconsidered
 * branchFromThought (integer): If branching, which thought number is the branching point
 * branchId (string): Identifier for the current branch (if any)
+	- Requires branchFromThought, on every thought of the branch
+	- Combined with isRevision and branchFromThought, the revision is recorded on that branch
 * needsMoreThoughts (boolean): If reaching end but realizing more thoughts needed
+* confidence (number): How confident you are in this thought, from 0.0 (guess) to 1.0 (certain)
+	- Low confidence thoughts are flagged so a later pass can revisit the weak links
+	- A revision can restate a thought with an updated confidence
 
 You should:
 1. Start with an initial estimate of needed thoughts, but be ready to adjust
@@ -104,54 +112,183 @@ You should:
 
 // ThoughtData represents the input data for a thought.
 type ThoughtData struct {
-	Thought           string `json:"thought"`
-	NextThoughtNeeded bool   `json:"nextThoughtNeeded"`
-	ThoughtNumber     int    `json:"thoughtNumber"`
-	TotalThoughts     int    `json:"totalThoughts"`
-	IsRevision        bool   `json:"isRevision,omitzero"`
-	RevisesThought    int    `json:"revisesThought,omitzero"`
-	BranchFromThought int    `json:"branchFromThought,omitzero"`
-	BranchId          string `json:"branchId,omitzero"`
-	NeedsMoreThoughts bool   `json:"needsMoreThoughts,omitzero"`
+	Thought           string   `json:"thought"`
+	NextThoughtNeeded bool     `json:"nextThoughtNeeded"`
+	ThoughtNumber     int      `json:"thoughtNumber"`
+	TotalThoughts     int      `json:"totalThoughts"`
+	IsRevision        bool     `json:"isRevision,omitzero"`
+	RevisesThought    int      `json:"revisesThought,omitzero"`
+	BranchFromThought int      `json:"branchFromThought,omitzero"`
+	BranchId          string   `json:"branchId,omitzero"`
+	NeedsMoreThoughts bool     `json:"needsMoreThoughts,omitzero"`
+	Confidence        *float64 `json:"confidence,omitzero"`
 }
 
+// ThoughtResult is the structured result of processing a thought.
+type ThoughtResult struct {
+	ThoughtNumber        int      `json:"thoughtNumber" jsonschema:"current thought number"`
+	TotalThoughts        int      `json:"totalThoughts" jsonschema:"estimated total thoughts, at least thoughtNumber"`
+	NextThoughtNeeded    bool     `json:"nextThoughtNeeded" jsonschema:"whether another thought step is needed"`
+	Branches             []string `json:"branches" jsonschema:"sorted identifiers of the recorded branches"`
+	ThoughtHistoryLength int      `json:"thoughtHistoryLength" jsonschema:"number of thoughts recorded so far"`
+}
+
+// Options configures a [SequentialThinkingServer].
+type Options struct {
+	// StateDir is the directory the thinking state is loaded from and persisted to.
+	// If empty, the state is kept in memory only.
+	StateDir string
+
+	// MaxThoughts is the maximum number of thoughts recorded, not counting revisions and the first thought of each branch.
+	// If zero, the number of thoughts is unlimited.
+	MaxThoughts int
+
+	// LowConfidence is the confidence below which a thought is flagged with a warning marker when logged.
+	// If zero, thoughts are never flagged.
+	LowConfidence float64
+
+	// ProgressLogLevel is the level of the MCP logging notification sent to the client for each recorded thought.
+	// If empty, no notifications are sent.
+	ProgressLogLevel mcp.LoggingLevel
+
+	// SkipRevisionProgress skips the progress notifications of revisions.
+	SkipRevisionProgress bool
+
+	// MaxThoughtBytes is the maximum size of a thought in bytes.
+	// If zero, the size is unlimited.
+	MaxThoughtBytes int
+}
+
+// loggingLevels are the valid MCP logging levels.
+var loggingLevels = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
 // SequentialThinkingServer implements the sequential thinking logic.
 type SequentialThinkingServer struct {
 	thoughtHistory        []ThoughtData
 	branches              map[string][]ThoughtData
 	disableThoughtLogging bool
+	stateDir              string
+	maxThoughts           int
+	lowConfidence         float64
+	progressLogLevel      mcp.LoggingLevel
+	skipRevisionProgress  bool
+	maxThoughtBytes       int
 	mu                    sync.Mutex
+
+	// stateVersion counts the changes of the thinking state, guarded by mu.
+	stateVersion uint64
+	// savedVersion is the stateVersion last written to the state directory, guarded by saveMu.
+	savedVersion uint64
+	saveMu       sync.Mutex
 }
 
 // NewSequentialThinkingServer creates a new instance of the server.
-func NewSequentialThinkingServer() *SequentialThinkingServer {
+//
+// If opts is nil, the default options are used.
+func NewSequentialThinkingServer(opts *Options) (*SequentialThinkingServer, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	if opts.ProgressLogLevel != "" && !slices.Contains(loggingLevels, opts.ProgressLogLevel) {
+		return nil, fmt.Errorf("invalid progress log level %q: must be one of %v", opts.ProgressLogLevel, loggingLevels)
+	}
+
 	disableLogging := false
 	val := os.Getenv("DISABLE_THOUGHT_LOGGING")
 	if ok, err := strconv.ParseBool(val); err == nil && ok {
 		disableLogging = true
 	}
 
-	return &SequentialThinkingServer{
+	s := &SequentialThinkingServer{
 		thoughtHistory:        make([]ThoughtData, 0),
 		branches:              make(map[string][]ThoughtData),
 		disableThoughtLogging: disableLogging,
+		stateDir:              opts.StateDir,
+		maxThoughts:           opts.MaxThoughts,
+		lowConfidence:         opts.LowConfidence,
+		progressLogLevel:      opts.ProgressLogLevel,
+		skipRevisionProgress:  opts.SkipRevisionProgress,
+		maxThoughtBytes:       opts.MaxThoughtBytes,
 	}
+
+	if s.stateDir != "" {
+		if err := s.loadState(); err != nil {
+			return nil, fmt.Errorf("load state: %w", err)
+		}
+	}
+
+	return s, nil
 }
 
-// validateThoughtData validates the input thought data.
+// maxThoughtsAhead is how far the totalThoughts estimate of a new thought may exceed its thoughtNumber.
+//
+// The bound is relative, since the echoed estimate grows with thoughtNumber: a long chain of thoughts is never cut short,
+// only an estimate far out of proportion to the progress so far is rejected.
+const maxThoughtsAhead = 1000
+
+// validateThoughtData validates the input thought data of a new thought.
 func (s *SequentialThinkingServer) validateThoughtData(input ThoughtData) error {
-	if input.Thought == "" {
-		return errors.New("invalid thought: must be a string")
+	if strings.TrimSpace(input.Thought) == "" {
+		return errors.New("invalid thought: must be a non-empty string")
+	}
+	if err := validateRecordedThought(input); err != nil {
+		return err
+	}
+	if input.TotalThoughts-input.ThoughtNumber > maxThoughtsAhead {
+		return fmt.Errorf("invalid totalThoughts: must be at most %d more than thoughtNumber; give a rough estimate and adjust it as you go", maxThoughtsAhead)
+	}
+	if input.IsRevision && input.RevisesThought == 0 {
+		return errors.New("invalid revisesThought: must be set to the revised thought number when isRevision is true")
+	}
+	return nil
+}
+
+// validateRecordedThought checks the invariants every recorded thought holds.
+//
+// Loaded thoughts are only checked against these, so that tightening the validation of new thoughts never drops saved history.
+func validateRecordedThought(thought ThoughtData) error {
+	if thought.Thought == "" {
+		return errors.New("invalid thought: must be a non-empty string")
 	}
-	if input.ThoughtNumber <= 0 {
+	if thought.ThoughtNumber <= 0 {
 		return errors.New("invalid thoughtNumber: must be a number > 0")
 	}
-	if input.TotalThoughts <= 0 {
+	if thought.TotalThoughts <= 0 {
 		return errors.New("invalid totalThoughts: must be a number > 0")
 	}
+	if thought.RevisesThought < 0 {
+		return errors.New("invalid revisesThought: must be a number > 0")
+	}
+	if thought.BranchFromThought < 0 {
+		return errors.New("invalid branchFromThought: must be a number > 0")
+	}
+	if thought.Confidence != nil && (*thought.Confidence < 0 || *thought.Confidence > 1) {
+		return errors.New("invalid confidence: must be a number between 0 and 1")
+	}
 	return nil
 }
 
+// countThoughts returns the number of recorded thoughts that count against the max thoughts limit.
+//
+// Revisions and the first thought of each branch, which creates it, are exempt. The later thoughts of a branch count,
+// since they carry branchFromThought too and would otherwise let a loop go on forever on a branch.
+// The caller must hold s.mu.
+func (s *SequentialThinkingServer) countThoughts() int {
+	n := 0
+	created := make(map[string]bool, len(s.branches))
+	for _, thought := range s.thoughtHistory {
+		createsBranch := thought.BranchFromThought > 0 && !created[thought.BranchId]
+		if thought.BranchFromThought > 0 {
+			created[thought.BranchId] = true
+		}
+		if !thought.IsRevision && !createsBranch {
+			n++
+		}
+	}
+	return n
+}
+
 // formatThought formats the thought for logging.
 func (s *SequentialThinkingServer) formatThought(thoughtData ThoughtData) string {
 	// Plain text components
@@ -161,11 +298,15 @@ func (s *SequentialThinkingServer) formatThought(thoughtData ThoughtData) string
 	switch {
 	case thoughtData.IsRevision:
 		prefixText = "🔄 Revision"
-		if thoughtData.RevisesThought < 0 {
+		if thoughtData.RevisesThought > 0 {
 			context = fmt.Sprintf(" (revising thought %d)", thoughtData.RevisesThought)
 		}
+		// A revision may also be recorded on a branch, mention it so the branch isn't silently hidden
+		if thoughtData.BranchFromThought > 0 {
+			context += fmt.Sprintf(" (on branch from thought %d, ID: %s)", thoughtData.BranchFromThought, thoughtData.BranchId)
+		}
 
-	case thoughtData.BranchFromThought < 0:
+	case thoughtData.BranchFromThought > 0:
 		prefixText = "🌿 Branch"
 		branchID := ""
 		if thoughtData.BranchId != "" {
@@ -178,6 +319,13 @@ func (s *SequentialThinkingServer) formatThought(thoughtData ThoughtData) string
 		context = ""
 	}
 
+	if thoughtData.Confidence != nil {
+		context += fmt.Sprintf(" [confidence %.0f%%]", *thoughtData.Confidence*100)
+		if *thoughtData.Confidence < s.lowConfidence {
+			context += " ⚠"
+		}
+	}
+
 	headerContent := fmt.Sprintf("%s %d/%d%s", prefixText, thoughtData.ThoughtNumber, thoughtData.TotalThoughts, context)
 
 	// Colors
@@ -190,7 +338,7 @@ func (s *SequentialThinkingServer) formatThought(thoughtData ThoughtData) string
 	switch {
 	case thoughtData.IsRevision:
 		coloredPrefix = yellow + prefixText + reset
-	case thoughtData.BranchFromThought < 0:
+	case thoughtData.BranchFromThought > 0:
 		coloredPrefix = green + prefixText + reset
 	default:
 		coloredPrefix = blue + prefixText + reset
@@ -219,13 +367,43 @@ func (s *SequentialThinkingServer) formatThought(thoughtData ThoughtData) string
 }
 
 // ProcessThought processes a thought request.
-func (s *SequentialThinkingServer) ProcessThought(ctx context.Context, request *mcp.CallToolRequest, input ThoughtData) (*mcp.CallToolResult, any, error) {
+func (s *SequentialThinkingServer) ProcessThought(ctx context.Context, request *mcp.CallToolRequest, input ThoughtData) (*mcp.CallToolResult, *ThoughtResult, error) {
 	s.mu.Lock()
 
 	if err := s.validateThoughtData(input); err != nil {
 		s.mu.Unlock()
 		return nil, nil, err
 	}
+	if s.maxThoughtBytes > 0 && len(input.Thought) > s.maxThoughtBytes {
+		s.mu.Unlock()
+		return nil, nil, fmt.Errorf("invalid thought: %d bytes exceeds the limit of %d bytes; shorten it or split it into several thoughts", len(input.Thought), s.maxThoughtBytes)
+	}
+
+	if input.BranchFromThought > 0 {
+		if input.BranchId == "" {
+			s.mu.Unlock()
+			return nil, nil, errors.New("invalid branchId: must be set when branchFromThought is set")
+		}
+		// The history also holds revisions and branch thoughts, so its length isn't the highest thought number
+		recorded := slices.ContainsFunc(s.thoughtHistory, func(thought ThoughtData) bool {
+			return thought.ThoughtNumber == input.BranchFromThought
+		})
+		if !recorded {
+			s.mu.Unlock()
+			return nil, nil, fmt.Errorf("invalid branchFromThought: thought %d has not been recorded", input.BranchFromThought)
+		}
+	} else if input.BranchId != "" {
+		// Only thoughts with a branch point are recorded on a branch, don't drop the thought from it silently
+		s.mu.Unlock()
+		return nil, nil, errors.New("invalid branchFromThought: must be set when branchId is set, also on the later thoughts of a branch")
+	}
+
+	_, branchExists := s.branches[input.BranchId]
+	createsBranch := input.BranchFromThought > 0 && !branchExists
+	if s.maxThoughts > 0 && !input.IsRevision && !createsBranch && s.countThoughts() >= s.maxThoughts {
+		s.mu.Unlock()
+		return nil, nil, fmt.Errorf("reached max of %d thoughts", s.maxThoughts)
+	}
 
 	if input.ThoughtNumber > input.TotalThoughts {
 		input.TotalThoughts = input.ThoughtNumber
@@ -233,7 +411,7 @@ func (s *SequentialThinkingServer) ProcessThought(ctx context.Context, request *
 
 	s.thoughtHistory = append(s.thoughtHistory, input)
 
-	if input.BranchFromThought < 0 && input.BranchId != "" {
+	if input.BranchFromThought > 0 {
 		branchID := input.BranchId
 		if _, exists := s.branches[branchID]; !exists {
 			s.branches[branchID] = make([]ThoughtData, 0)
@@ -241,25 +419,42 @@ func (s *SequentialThinkingServer) ProcessThought(ctx context.Context, request *
 		s.branches[branchID] = append(s.branches[branchID], input)
 	}
 
+	var (
+		stateVersion uint64
+		state        thinkingState
+	)
+	if s.stateDir != "" {
+		s.stateVersion++
+		stateVersion = s.stateVersion
+		state = s.snapshot()
+	}
+
 	if !s.disableThoughtLogging {
 		formatted := s.formatThought(input)
 		fmt.Fprintln(os.Stderr, formatted)
 	}
 
 	// Prepare response
-	branches := slices.Sorted(maps.Keys(s.branches))
-
-	response := map[string]any{
-		"thoughtNumber":        input.ThoughtNumber,
-		"totalThoughts":        input.TotalThoughts,
-		"nextThoughtNeeded":    input.NextThoughtNeeded,
-		"branches":             branches,
-		"thoughtHistoryLength": len(s.thoughtHistory),
+	result := &ThoughtResult{
+		ThoughtNumber:        input.ThoughtNumber,
+		TotalThoughts:        input.TotalThoughts,
+		NextThoughtNeeded:    input.NextThoughtNeeded,
+		Branches:             slices.AppendSeq(make([]string, 0, len(s.branches)), maps.Keys(s.branches)),
+		ThoughtHistoryLength: len(s.thoughtHistory),
 	}
+	slices.Sort(result.Branches)
 
 	s.mu.Unlock()
 
-	data, err := gson.MarshalIndentBy(sonic.ConfigFastest, response, "", "  ")
+	if s.stateDir != "" {
+		if err := s.saveState(stateVersion, state); err != nil {
+			slog.ErrorContext(ctx, "persist thinking state", slog.Any("error", err))
+		}
+	}
+
+	s.logProgress(ctx, request, input)
+
+	data, err := gson.MarshalIndentBy(sonic.ConfigFastest, result, "", "  ")
 	if err != nil {
 		return nil, nil, fmt.Errorf("marshal response: %w", err)
 	}
@@ -270,37 +465,108 @@ func (s *SequentialThinkingServer) ProcessThought(ctx context.Context, request *
 				Text: string(data),
 			},
 		},
-	}, nil, nil
+	}, result, nil
+}
+
+// logProgress sends the recorded thought to the client as an MCP logging notification, so that it can show the progress live.
+func (s *SequentialThinkingServer) logProgress(ctx context.Context, request *mcp.CallToolRequest, thought ThoughtData) {
+	if s.progressLogLevel == "" || request == nil || request.Session == nil {
+		return
+	}
+	if thought.IsRevision && s.skipRevisionProgress {
+		return
+	}
+
+	params := &mcp.LoggingMessageParams{
+		Logger: "sequential-thinking",
+		Level:  s.progressLogLevel,
+		Data:   thought,
+	}
+	if err := request.Session.Log(ctx, params); err != nil {
+		slog.WarnContext(ctx, "send thought progress", slog.Any("error", err))
+	}
+}
+
+// version is the version of the sequential-thinking server.
+const version = "0.0.1"
+
+// shutdownTimeout is how long the HTTP server waits for in-flight requests to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// newHTTPMux returns the mux of the HTTP mode, serving the MCP endpoint with mcpHandler at the root
+// and the operational endpoints beside it.
+//
+// The operational endpoints are exempt from the bearer token mcpHandler may require: probes and scrapers usually
+// can't send one, and they only expose counts, never thought content.
+func newHTTPMux(mcpHandler, metrics, healthz, readyz http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/", mcpHandler)
+	mux.Handle("GET /metrics", metrics)
+	mux.Handle("GET /healthz", healthz)
+	mux.Handle("GET /readyz", readyz)
+	return mux
+}
+
+// endStreams wraps next so that the GET requests it serves, the standalone SSE streams of MCP sessions,
+// are canceled along with shutdown, which is canceled when the HTTP server begins shutting down.
+//
+// [http.Server.Shutdown] waits for every connection to go idle, which an open stream never does. Other requests keep their
+// contexts, so that the responses of tool calls still running are written before their connections close.
+func endStreams(shutdown context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(shutdown, cancel)
+		defer stop()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func ptr[T any](v T) *T {
 	return &v
 }
 
-var httpAddr string
+var (
+	httpAddr             string
+	stateDir             string
+	stateFile            string
+	maxThoughts          int
+	lowConfidence        float64
+	progressLogLevel     string
+	skipRevisionProgress bool
+	maxThoughtBytes      int
+	authToken            string
+	isolateClients       bool
+	adminToken           string
+)
 
 func init() {
 	uuid.EnableRandPool()
 
-	flag.StringVar(&httpAddr, "http", "", "if set, use streamable HTTP at this address, instead of stdin/stdout")
+	flag.StringVar(&httpAddr, "http", "", "if set, use streamable HTTP at this address, instead of stdin/stdout. Prometheus metrics are served at /metrics, and health checks at /healthz and /readyz")
+	flag.StringVar(&stateDir, "state-dir", "", "if set, persist the thinking state as JSON in this directory and restore it at startup (can't be used with -state-file)")
+	flag.StringVar(&stateFile, "state-file", "", "if set, load the thinking state from this JSON file at startup and write a snapshot to it on shutdown (can't be used with -state-dir)")
+	flag.IntVar(&maxThoughts, "max-thoughts", 0, "maximum number of thoughts, not counting revisions and the thoughts creating branches (0 means unlimited)")
+	flag.Float64Var(&lowConfidence, "low-confidence", 0.5, "flag logged thoughts whose confidence is below this threshold with a warning marker (0 disables)")
+	flag.StringVar(&progressLogLevel, "progress-log-level", "", "if set, send an MCP logging notification at this level for each recorded thought, e.g. info")
+	flag.BoolVar(&skipRevisionProgress, "skip-revision-progress", false, "don't send progress notifications for revisions")
+	flag.IntVar(&maxThoughtBytes, "max-thought-bytes", 0, "maximum size of a thought in bytes, including revisions (0 means unlimited)")
+	flag.StringVar(&authToken, "auth-token", os.Getenv("MCP_AUTH_TOKEN"), "comma-separated bearer tokens required by the HTTP MCP endpoint (defaults to $MCP_AUTH_TOKEN; empty disables authentication). /metrics, /healthz and /readyz stay unauthenticated for probes and scrapers")
+	flag.BoolVar(&isolateClients, "isolate-clients", false, "in HTTP mode, give each client session its own thinking state instead of sharing one between all clients (can't be used with -state-dir or -state-file). /healthz, /readyz and /metrics then report the sum over the connected clients")
+	flag.StringVar(&adminToken, "admin-token", os.Getenv("MCP_ADMIN_TOKEN"), "comma-separated bearer tokens of admin clients (defaults to $MCP_ADMIN_TOKEN; requires -isolate-clients). Admin clients get a read-only search_thoughts across the thoughts of every connected client")
 }
 
-func main() {
-	flag.Parse()
-
-	logpath := cmp.Or(os.Getenv("SEQUENTIAL_THINKING_LOG"), filepath.Join(os.TempDir(), "sequential-thinking-server.log"))
-	f, err := os.OpenFile(logpath, os.O_RDWR|os.O_CREATE, 0o666)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer f.Close()
-
-	logger := slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
-	slog.SetDefault(logger)
-
+// newMCPServer creates the MCP server serving the tools and prompts of st.
+func newMCPServer(logger *slog.Logger, st *SequentialThinkingServer) (*mcp.Server, error) {
 	srvImpl := &mcp.Implementation{
 		Name:    "sequential-thinking",
-		Version: "0.0.1",
+		Version: version,
 	}
 	opts := &mcp.ServerOptions{
 		// TODO(zchee): The [mcp.ServerOptions.Instructions] are usually enough tool description, but set a global prompt such as "Think step by step"
@@ -320,6 +586,7 @@ func main() {
 			"thought": {
 				Type:        "string",
 				Description: "Your current thinking step",
+				MinLength:   ptr(1),
 			},
 			"nextThoughtNeeded": {
 				Type:        "boolean",
@@ -332,7 +599,7 @@ func main() {
 			},
 			"totalThoughts": {
 				Type:        "integer",
-				Description: "Estimated total thoughts needed (numeric value, e.g., 5, 10)",
+				Description: fmt.Sprintf("Estimated total thoughts needed (numeric value, e.g., 5, 10), at most %d more than thoughtNumber", maxThoughtsAhead),
 				Minimum:     ptr(float64(1)),
 			},
 			"isRevision": {
@@ -357,6 +624,12 @@ func main() {
 				Type:        "boolean",
 				Description: "If more thoughts are needed",
 			},
+			"confidence": {
+				Type:        "number",
+				Description: "Confidence in this thought, from 0.0 to 1.0",
+				Minimum:     ptr(float64(0)),
+				Maximum:     ptr(float64(1)),
+			},
 		},
 		Required: []string{
 			"thought",
@@ -370,30 +643,189 @@ func main() {
 		Description: description,
 		InputSchema: schema,
 	}
-	sequentialThinkServer := NewSequentialThinkingServer()
+	mcp.AddTool(srv, sequentialThinkingTool, st.ProcessThought)
 
-	mcp.AddTool(srv, sequentialThinkingTool, sequentialThinkServer.ProcessThought)
+	searchThoughtsSchema, err := searchThoughtsInputSchema()
+	if err != nil {
+		return nil, err
+	}
+	searchThoughtsTool := &mcp.Tool{
+		Name:        "search_thoughts",
+		Description: "Search the recorded thoughts, including branch thoughts, for a case-insensitive substring or regular expression",
+		InputSchema: searchThoughtsSchema,
+	}
+	mcp.AddTool(srv, searchThoughtsTool, st.SearchThoughts)
+
+	srv.AddPrompt(structuredAnalysisPrompt(), StructuredAnalysis)
+
+	srv.AddReceivingMiddleware(traceToolCalls)
+
+	return srv, nil
+}
+
+// searchThoughtsInputSchema returns the input schema of the search_thoughts tool.
+func searchThoughtsInputSchema() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[SearchThoughtsArgs](nil)
+	if err != nil {
+		return nil, fmt.Errorf("infer search_thoughts input schema: %w", err)
+	}
+	schema.Properties["query"].MinLength = ptr(1)
+	schema.Properties["limit"].Minimum = ptr(float64(0))
+	return schema, nil
+}
+
+func main() {
+	started := time.Now()
+	flag.Parse()
+
+	logpath := cmp.Or(os.Getenv("SEQUENTIAL_THINKING_LOG"), filepath.Join(os.TempDir(), "sequential-thinking-server.log"))
+	f, err := os.OpenFile(logpath, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	logger := slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	slog.SetDefault(logger)
+
+	if stateDir != "" && stateFile != "" {
+		logger.Error("-state-dir and -state-file can't be used together")
+		os.Exit(1)
+	}
+	if isolateClients && (httpAddr == "" || stateDir != "" || stateFile != "") {
+		logger.Error("-isolate-clients requires -http and can't be used with -state-dir or -state-file")
+		os.Exit(1)
+	}
+	if adminToken != "" && !isolateClients {
+		logger.Error("-admin-token requires -isolate-clients")
+		os.Exit(1)
+	}
+
+	clientOpts := &Options{
+		MaxThoughts:          maxThoughts,
+		LowConfidence:        lowConfidence,
+		ProgressLogLevel:     mcp.LoggingLevel(progressLogLevel),
+		SkipRevisionProgress: skipRevisionProgress,
+		MaxThoughtBytes:      maxThoughtBytes,
+	}
+	sharedOpts := *clientOpts
+	sharedOpts.StateDir = stateDir
+	sequentialThinkServer, err := NewSequentialThinkingServer(&sharedOpts)
+	if err != nil {
+		logger.Error("create sequential thinking server", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if stateFile != "" {
+		if err := sequentialThinkServer.LoadFile(stateFile); err != nil {
+			logger.Error("load state snapshot", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+	saveSnapshot := func(ctx context.Context) {
+		if stateFile == "" {
+			return
+		}
+		if err := sequentialThinkServer.SaveFile(stateFile); err != nil {
+			logger.ErrorContext(ctx, "save state snapshot", slog.Any("error", err))
+		}
+	}
+
+	srv, err := newMCPServer(logger, sequentialThinkServer)
+	if err != nil {
+		logger.Error("create mcp server", slog.Any("error", err))
+		os.Exit(1)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	tp, err := initTracer(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "init tracer", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if tp != nil {
+		defer func() {
+			// ctx is canceled by the time the server stops, so the batched spans of the last tool calls get a deadline of their own
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+			defer cancel()
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				logger.ErrorContext(ctx, "shutdown tracer provider", slog.Any("error", err))
+			}
+		}()
+	}
+
 	if httpAddr != "" {
+		srv.AddReceivingMiddleware(instrumentToolCalls)
+
 		mcpServer := func(*http.Request) *mcp.Server {
 			return srv
 		}
-		handler := mcp.NewStreamableHTTPHandler(mcpServer, nil)
+		// The operational endpoints report the shared state, or the sum over the connected clients when they are isolated
+		counts := sequentialThinkServer.counts
+		healthz := sequentialThinkServer.HealthHandler(started)
+		readyz := sequentialThinkServer.ReadyHandler(started)
+		authTokens := parseAuthTokens(authToken)
+		adminTokens := parseAuthTokens(adminToken)
+		var clients *clientStates
+		if isolateClients {
+			clients = newClientStates()
+			counts = clients.counts
+			healthz = healthHandler(started, clients.counts, nil)
+			readyz = healthHandler(started, clients.counts, nil)
+
+			// Called once per new client session, so each client gets its own thinking state
+			mcpServer, err = clients.servers(logger, clientOpts, adminTokens)
+			if err != nil {
+				logger.ErrorContext(ctx, "create admin mcp server", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}
+		if err := registerMetrics(prometheus.DefaultRegisterer, counts); err != nil {
+			logger.ErrorContext(ctx, "register metrics", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		shutdown, shutdownStarted := context.WithCancel(context.Background())
+		defer shutdownStarted()
+		var handler http.Handler = endStreams(shutdown, mcp.NewStreamableHTTPHandler(mcpServer, nil))
+		if isolateClients {
+			handler = clients.requireAdminSessionToken(adminTokens, handler)
+		}
+		if len(authTokens) > 0 {
+			// Admin clients authenticate with their own tokens
+			handler = requireBearerToken(append(authTokens, adminTokens...), handler)
+		}
 		httpSrv := &http.Server{
 			Addr:    httpAddr,
-			Handler: handler,
+			Handler: newHTTPMux(handler, promhttp.Handler(), healthz, readyz),
 			BaseContext: func(net.Listener) context.Context {
-				return ctx
+				// Detach from the signal context so in-flight requests can finish while shutting down
+				return context.WithoutCancel(ctx)
 			},
 		}
+		httpSrv.RegisterOnShutdown(shutdownStarted)
+		shutdownDone := make(chan struct{})
+		go func() {
+			defer close(shutdownDone)
+			<-ctx.Done()
+			logger.InfoContext(ctx, "shutting down sequential thinking mcp http server")
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+			defer cancel()
+			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+				logger.ErrorContext(ctx, "shutdown sequential thinking mcp http server", slog.Any("error", err))
+				httpSrv.Close()
+			}
+		}()
 		logger.InfoContext(ctx, "sequential thinking MCP server running", slog.String("addr", "http://"+httpAddr))
-		if err := httpSrv.ListenAndServe(); err != nil {
+		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.ErrorContext(ctx, "serve sequential thinking mcp http server", slog.Any("error", err))
+			saveSnapshot(ctx)
 			os.Exit(1)
 		}
+		<-shutdownDone
+		saveSnapshot(ctx)
 		return
 	}
 
@@ -403,7 +835,9 @@ func main() {
 		Writer:    f,
 	}
 	logger.InfoContext(ctx, "sequential thinking mcp server running on stdio")
-	if err := srv.Run(ctx, tr); err != nil {
+	err = srv.Run(ctx, tr)
+	saveSnapshot(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
 		logger.ErrorContext(ctx, "serve sequential thinking mcp stdio server", slog.Any("error", err))
 		os.Exit(1)
 	}
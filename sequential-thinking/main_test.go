@@ -0,0 +1,678 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTestServer creates a server with opts that doesn't log thoughts to stderr.
+func newTestServer(t *testing.T, opts *Options) *SequentialThinkingServer {
+	t.Helper()
+
+	t.Setenv("DISABLE_THOUGHT_LOGGING", "true")
+	s, err := NewSequentialThinkingServer(opts)
+	if err != nil {
+		t.Fatalf("NewSequentialThinkingServer: %v", err)
+	}
+
+	return s
+}
+
+func TestProcessThoughtMaxThoughts(t *testing.T) {
+	const maxThoughts = 3
+	s := newTestServer(t, &Options{MaxThoughts: maxThoughts})
+
+	for i := 1; i <= maxThoughts; i++ {
+		thought := ThoughtData{
+			Thought:           fmt.Sprintf("thought %d", i),
+			NextThoughtNeeded: true,
+			ThoughtNumber:     i,
+			TotalThoughts:     maxThoughts + 1,
+		}
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			t.Fatalf("ProcessThought(%d): %v", i, err)
+		}
+	}
+
+	next := ThoughtData{
+		Thought:           "one too many",
+		NextThoughtNeeded: false,
+		ThoughtNumber:     maxThoughts + 1,
+		TotalThoughts:     maxThoughts + 1,
+	}
+	_, _, err := s.ProcessThought(t.Context(), nil, next)
+	if want := fmt.Sprintf("reached max of %d thoughts", maxThoughts); err == nil || err.Error() != want {
+		t.Fatalf("ProcessThought over the limit: got error %v, want %q", err, want)
+	}
+	if got := len(s.thoughtHistory); got != maxThoughts {
+		t.Errorf("thought history length = %d, want %d", got, maxThoughts)
+	}
+
+	// Revisions don't count against the limit
+	revision := ThoughtData{
+		Thought:           "revised thought",
+		NextThoughtNeeded: false,
+		ThoughtNumber:     maxThoughts,
+		TotalThoughts:     maxThoughts,
+		IsRevision:        true,
+		RevisesThought:    1,
+	}
+	if _, _, err := s.ProcessThought(t.Context(), nil, revision); err != nil {
+		t.Errorf("ProcessThought(revision) at the limit: %v", err)
+	}
+
+	// Only the thought creating a branch is exempt, so a loop that stays on the branch still hits the limit
+	s = newTestServer(t, &Options{MaxThoughts: maxThoughts})
+	root := ThoughtData{
+		Thought:           "root thought",
+		NextThoughtNeeded: true,
+		ThoughtNumber:     1,
+		TotalThoughts:     2,
+	}
+	if _, _, err := s.ProcessThought(t.Context(), nil, root); err != nil {
+		t.Fatalf("ProcessThought(root): %v", err)
+	}
+	accepted := 0
+	for i := range 2 * maxThoughts {
+		thought := ThoughtData{
+			Thought:           fmt.Sprintf("branch thought %d", i),
+			NextThoughtNeeded: true,
+			ThoughtNumber:     i + 2,
+			TotalThoughts:     i + 3,
+			BranchFromThought: 1,
+			BranchId:          "loop",
+		}
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			if want := fmt.Sprintf("reached max of %d thoughts", maxThoughts); err.Error() != want {
+				t.Fatalf("ProcessThought(branch thought %d): got error %v, want %q", i, err, want)
+			}
+			break
+		}
+		accepted++
+	}
+	// The thought creating the branch, then the branch thoughts counted along with the root thought up to the limit
+	if want := 1 + maxThoughts - 1; accepted != want {
+		t.Errorf("accepted %d thoughts on the branch, want %d", accepted, want)
+	}
+}
+
+func TestSequentialThinkingStructuredContent(t *testing.T) {
+	srv, err := newMCPServer(slog.New(slog.DiscardHandler), newTestServer(t, nil))
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	cs := connectClient(t, srv)
+
+	res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+		Name: "sequentialthinking",
+		Arguments: map[string]any{
+			"thought":           "first",
+			"nextThoughtNeeded": true,
+			"thoughtNumber":     1,
+			"totalThoughts":     3,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("sequentialthinking failed: %v", res.Content)
+	}
+
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatalf("marshal structured content: %v", err)
+	}
+	// Without any branch, branches is an empty array rather than null, as the output schema requires
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal structured content: %v", err)
+	}
+	if got := string(raw["branches"]); got != "[]" {
+		t.Errorf("structured content branches = %s, want []", got)
+	}
+
+	var got ThoughtResult
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal structured content into ThoughtResult: %v", err)
+	}
+	want := ThoughtResult{
+		ThoughtNumber:        1,
+		TotalThoughts:        3,
+		NextThoughtNeeded:    true,
+		Branches:             []string{},
+		ThoughtHistoryLength: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("structured content = %+v, want %+v", got, want)
+	}
+}
+
+// listTools connects a client to the MCP server of s over an in-memory transport and returns the input schema of each listed tool by name.
+func listTools(t *testing.T, s *SequentialThinkingServer) map[string]*jsonschema.Schema {
+	t.Helper()
+
+	srv, err := newMCPServer(slog.New(slog.DiscardHandler), s)
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.Connect(t.Context(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	t.Cleanup(func() { ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	cs, err := client.Connect(t.Context(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+
+	res, err := cs.ListTools(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+
+	schemas := make(map[string]*jsonschema.Schema, len(res.Tools))
+	for _, tool := range res.Tools {
+		// The client sees the schema as decoded JSON, so round-trip it into a Schema
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			t.Fatalf("marshal %s input schema: %v", tool.Name, err)
+		}
+		var schema jsonschema.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("unmarshal %s input schema: %v", tool.Name, err)
+		}
+		schemas[tool.Name] = &schema
+	}
+
+	return schemas
+}
+
+func TestInputSchemas(t *testing.T) {
+	schemas := listTools(t, newTestServer(t, nil))
+
+	type bounds struct {
+		minLength *int
+		minimum   *float64
+		maximum   *float64
+	}
+	tests := map[string]struct {
+		required   []string
+		properties map[string]bounds
+	}{
+		"sequentialthinking": {
+			required: []string{"thought", "nextThoughtNeeded", "thoughtNumber", "totalThoughts"},
+			properties: map[string]bounds{
+				"thought":           {minLength: ptr(1)},
+				"thoughtNumber":     {minimum: ptr(float64(1))},
+				"totalThoughts":     {minimum: ptr(float64(1))},
+				"revisesThought":    {minimum: ptr(float64(1))},
+				"branchFromThought": {minimum: ptr(float64(1))},
+				"confidence":        {minimum: ptr(float64(0)), maximum: ptr(float64(1))},
+			},
+		},
+		"search_thoughts": {
+			required: []string{"query"},
+			properties: map[string]bounds{
+				"query": {minLength: ptr(1)},
+				"limit": {minimum: ptr(float64(0))},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := schemas[name]
+			if !ok {
+				t.Fatalf("tool %s is not registered", name)
+			}
+
+			required := slices.Sorted(slices.Values(schema.Required))
+			if want := slices.Sorted(slices.Values(tt.required)); !slices.Equal(required, want) {
+				t.Errorf("required = %v, want %v", required, want)
+			}
+			for prop, want := range tt.properties {
+				got, ok := schema.Properties[prop]
+				if !ok {
+					t.Errorf("property %s is missing", prop)
+					continue
+				}
+				if !equalPtr(got.MinLength, want.minLength) {
+					t.Errorf("%s minLength = %v, want %v", prop, deref(got.MinLength), deref(want.minLength))
+				}
+				if !equalPtr(got.Minimum, want.minimum) {
+					t.Errorf("%s minimum = %v, want %v", prop, deref(got.Minimum), deref(want.minimum))
+				}
+				if !equalPtr(got.Maximum, want.maximum) {
+					t.Errorf("%s maximum = %v, want %v", prop, deref(got.Maximum), deref(want.maximum))
+				}
+			}
+		})
+	}
+}
+
+// equalPtr reports whether a and b are both nil or point to equal values.
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// deref returns the value v points to, or nil if v is nil, for printing.
+func deref[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestValidateThoughtData(t *testing.T) {
+	valid := ThoughtData{
+		Thought:           "thought",
+		NextThoughtNeeded: true,
+		ThoughtNumber:     1,
+		TotalThoughts:     3,
+	}
+	tests := map[string]struct {
+		modify  func(*ThoughtData)
+		wantErr string
+	}{
+		"Valid": {
+			modify: func(*ThoughtData) {},
+		},
+		"EmptyThought": {
+			modify:  func(td *ThoughtData) { td.Thought = "" },
+			wantErr: "invalid thought",
+		},
+		"BlankThought": {
+			modify:  func(td *ThoughtData) { td.Thought = " \n\t" },
+			wantErr: "invalid thought",
+		},
+		"ZeroThoughtNumber": {
+			modify:  func(td *ThoughtData) { td.ThoughtNumber = 0 },
+			wantErr: "invalid thoughtNumber",
+		},
+		"ThoughtNumberAboveTotal": {
+			modify: func(td *ThoughtData) { td.ThoughtNumber, td.NeedsMoreThoughts = 5, true },
+		},
+		"LongChain": {
+			// The server echoes totalThoughts as at least thoughtNumber, so a long chain sends both above maxThoughtsAhead
+			modify: func(td *ThoughtData) { td.ThoughtNumber, td.TotalThoughts = 1500, 1510 },
+		},
+		"ZeroTotalThoughts": {
+			modify:  func(td *ThoughtData) { td.TotalThoughts = 0 },
+			wantErr: "invalid totalThoughts",
+		},
+		"MaxThoughtsAhead": {
+			modify: func(td *ThoughtData) { td.TotalThoughts = td.ThoughtNumber + maxThoughtsAhead },
+		},
+		"TotalThoughtsTooFarAhead": {
+			modify:  func(td *ThoughtData) { td.TotalThoughts = td.ThoughtNumber + maxThoughtsAhead + 1 },
+			wantErr: "invalid totalThoughts",
+		},
+		"TotalThoughtsTooFarAheadOfLongChain": {
+			modify:  func(td *ThoughtData) { td.ThoughtNumber, td.TotalThoughts = 1500, 1500+maxThoughtsAhead+1 },
+			wantErr: "invalid totalThoughts",
+		},
+		"Revision": {
+			modify: func(td *ThoughtData) { td.ThoughtNumber, td.IsRevision, td.RevisesThought = 2, true, 1 },
+		},
+		"RevisionWithoutRevisesThought": {
+			modify:  func(td *ThoughtData) { td.IsRevision = true },
+			wantErr: "invalid revisesThought",
+		},
+		"NegativeRevisesThought": {
+			modify:  func(td *ThoughtData) { td.IsRevision, td.RevisesThought = true, -1 },
+			wantErr: "invalid revisesThought",
+		},
+		"NegativeBranchFromThought": {
+			modify:  func(td *ThoughtData) { td.BranchFromThought = -1 },
+			wantErr: "invalid branchFromThought",
+		},
+		"ConfidenceBounds": {
+			modify: func(td *ThoughtData) { td.Confidence = ptr(1.0) },
+		},
+		"ConfidenceAboveOne": {
+			modify:  func(td *ThoughtData) { td.Confidence = ptr(1.5) },
+			wantErr: "invalid confidence",
+		},
+		"NegativeConfidence": {
+			modify:  func(td *ThoughtData) { td.Confidence = ptr(-0.1) },
+			wantErr: "invalid confidence",
+		},
+	}
+	s := newTestServer(t, nil)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+
+			err := s.validateThoughtData(input)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("validateThoughtData: unexpected error %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Errorf("validateThoughtData: got error %v, want an error starting with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessThoughtRevisionsAndBranches(t *testing.T) {
+	tests := map[string]struct {
+		isRevision bool
+		branch     bool
+		wantHeader string
+	}{
+		"Thought": {
+			wantHeader: "💭 Thought 2/3",
+		},
+		"Revision": {
+			isRevision: true,
+			wantHeader: "🔄 Revision 2/3 (revising thought 1)",
+		},
+		"Branch": {
+			branch:     true,
+			wantHeader: "🌿 Branch 2/3 (from thought 1, ID: alt)",
+		},
+		"RevisionOnBranch": {
+			isRevision: true,
+			branch:     true,
+			wantHeader: "🔄 Revision 2/3 (revising thought 1) (on branch from thought 1, ID: alt)",
+		},
+	}
+	// The header is colored around the prefix, only its text is checked
+	uncolor := strings.NewReplacer(`\033[33m`, "", `\033[32m`, "", `\033[34m`, "", `\033[0m`, "")
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			first := ThoughtData{Thought: "first", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 3}
+			if _, _, err := s.ProcessThought(t.Context(), nil, first); err != nil {
+				t.Fatalf("ProcessThought(first): %v", err)
+			}
+
+			thought := ThoughtData{Thought: "second", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 3}
+			if tt.isRevision {
+				thought.IsRevision, thought.RevisesThought = true, 1
+			}
+			if tt.branch {
+				thought.BranchFromThought, thought.BranchId = 1, "alt"
+			}
+			_, res, err := s.ProcessThought(t.Context(), nil, thought)
+			if err != nil {
+				t.Fatalf("ProcessThought: %v", err)
+			}
+
+			// Every thought is recorded in the history, branch thoughts on their branch too
+			if got := s.thoughtHistory[len(s.thoughtHistory)-1]; got != thought {
+				t.Errorf("last thought in history = %+v, want %+v", got, thought)
+			}
+			if got, want := res.ThoughtHistoryLength, 2; got != want {
+				t.Errorf("thoughtHistoryLength = %d, want %d", got, want)
+			}
+			wantBranches := []string{}
+			if tt.branch {
+				wantBranches = []string{"alt"}
+				if got := s.branches["alt"]; len(got) != 1 || got[0] != thought {
+					t.Errorf("branch alt = %+v, want [%+v]", got, thought)
+				}
+			} else if len(s.branches) != 0 {
+				t.Errorf("branches = %v, want none", s.branches)
+			}
+			if !slices.Equal(res.Branches, wantBranches) {
+				t.Errorf("result branches = %q, want %q", res.Branches, wantBranches)
+			}
+
+			if got := uncolor.Replace(s.formatThought(thought)); !strings.Contains(got, "│ "+tt.wantHeader+" ") {
+				t.Errorf("formatThought header: got\n%s\nwant a header line of %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestProcessThoughtBranchValidation(t *testing.T) {
+	tests := map[string]struct {
+		branchFromThought int
+		branchID          string
+		wantErr           string
+	}{
+		"Fork": {
+			branchFromThought: 1,
+			branchID:          "alt",
+		},
+		"BranchFromThoughtWithoutBranchId": {
+			branchFromThought: 1,
+			wantErr:           "invalid branchId: must be set when branchFromThought is set",
+		},
+		"BranchIdWithoutBranchFromThought": {
+			branchID: "alt",
+			wantErr:  "invalid branchFromThought: must be set when branchId is set",
+		},
+		"UnrecordedThought": {
+			branchFromThought: 5,
+			branchID:          "alt",
+			wantErr:           "invalid branchFromThought: thought 5 has not been recorded",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			for i := 1; i <= 2; i++ {
+				thought := ThoughtData{Thought: fmt.Sprintf("thought %d", i), NextThoughtNeeded: true, ThoughtNumber: i, TotalThoughts: 3}
+				if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+					t.Fatalf("ProcessThought(%d): %v", i, err)
+				}
+			}
+
+			fork := ThoughtData{
+				Thought:           "fork",
+				NextThoughtNeeded: true,
+				ThoughtNumber:     3,
+				TotalThoughts:     3,
+				BranchFromThought: tt.branchFromThought,
+				BranchId:          tt.branchID,
+			}
+			_, _, err := s.ProcessThought(t.Context(), nil, fork)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("ProcessThought: got error %v, want an error starting with %q", err, tt.wantErr)
+				}
+				if got := len(s.thoughtHistory); got != 2 {
+					t.Errorf("thought history length after a rejected thought = %d, want 2", got)
+				}
+				if len(s.branches) != 0 {
+					t.Errorf("branches after a rejected thought = %v, want none", s.branches)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessThought: %v", err)
+			}
+			if got := s.branches[tt.branchID]; len(got) != 1 || got[0] != fork {
+				t.Errorf("branch %s = %+v, want [%+v]", tt.branchID, got, fork)
+			}
+		})
+	}
+}
+
+func TestProcessThoughtMaxThoughtBytes(t *testing.T) {
+	const maxThoughtBytes = 10
+	tests := map[string]struct {
+		thought string
+		wantErr string
+	}{
+		"UnderLimit": {
+			thought: "日日日", // 9 bytes
+		},
+		"AtLimit": {
+			thought: "日日日a", // 10 bytes
+		},
+		"OverLimit": {
+			// 4 runes is well under the limit, the 12 bytes are not
+			thought: "日日日日",
+			wantErr: "invalid thought: 12 bytes exceeds the limit of 10 bytes",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, &Options{MaxThoughtBytes: maxThoughtBytes})
+			thought := ThoughtData{Thought: tt.thought, NextThoughtNeeded: false, ThoughtNumber: 1, TotalThoughts: 1}
+
+			_, _, err := s.ProcessThought(t.Context(), nil, thought)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ProcessThought: unexpected error %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Errorf("ProcessThought: got error %v, want an error starting with %q", err, tt.wantErr)
+			}
+			wantLen := 1
+			if tt.wantErr != "" {
+				wantLen = 0
+			}
+			if got := len(s.thoughtHistory); got != wantLen {
+				t.Errorf("thought history length = %d, want %d", got, wantLen)
+			}
+		})
+	}
+}
+
+func TestFormatThoughtLowConfidence(t *testing.T) {
+	tests := map[string]struct {
+		lowConfidence float64
+		confidence    *float64
+		wantHeader    string
+		wantMarker    bool
+	}{
+		"BelowThreshold": {
+			lowConfidence: 0.5,
+			confidence:    ptr(0.4),
+			wantHeader:    "[confidence 40%] ⚠",
+			wantMarker:    true,
+		},
+		"AtThreshold": {
+			lowConfidence: 0.5,
+			confidence:    ptr(0.5),
+			wantHeader:    "[confidence 50%]",
+		},
+		"NoConfidence": {
+			lowConfidence: 0.5,
+		},
+		"Disabled": {
+			lowConfidence: 0,
+			confidence:    ptr(0.0),
+			wantHeader:    "[confidence 0%]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, &Options{LowConfidence: tt.lowConfidence})
+			got := s.formatThought(ThoughtData{Thought: "weak link", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 2, Confidence: tt.confidence})
+
+			if !strings.Contains(got, tt.wantHeader) {
+				t.Errorf("formatThought: got\n%s\nwant a header with %q", got, tt.wantHeader)
+			}
+			if marker := strings.Contains(got, "⚠"); marker != tt.wantMarker {
+				t.Errorf("formatThought: got\n%s\nwith the low-confidence marker %t, want %t", got, marker, tt.wantMarker)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsHistoryBeyondInputBounds(t *testing.T) {
+	s := newTestServer(t, nil)
+	const state = `{
+  "thoughtHistory": [
+    {"thought": "first", "nextThoughtNeeded": true, "thoughtNumber": 1, "totalThoughts": 1},
+    {"thought": "far along", "nextThoughtNeeded": true, "thoughtNumber": 1500, "totalThoughts": 1500},
+    {"thought": " ", "nextThoughtNeeded": false, "thoughtNumber": 1501, "totalThoughts": 1501},
+    {"thought": "", "nextThoughtNeeded": false, "thoughtNumber": 1502, "totalThoughts": 1502}
+  ],
+  "branches": {}
+}`
+	if err := s.Load(strings.NewReader(state)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// Only the empty thought, which was never accepted, is dropped
+	if got, want := len(s.thoughtHistory), 3; got != want {
+		t.Fatalf("loaded %d thoughts, want %d", got, want)
+	}
+	if got := s.thoughtHistory[1].ThoughtNumber; got != 1500 {
+		t.Errorf("thoughtNumber of the second thought = %d, want 1500", got)
+	}
+}
+
+func TestEndStreams(t *testing.T) {
+	shutdown, startShutdown := context.WithCancel(t.Context())
+	defer startShutdown()
+
+	release := make(chan struct{})
+	handler := endStreams(shutdown, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// An event stream only ends when its request context does
+			<-r.Context().Done()
+			return
+		}
+		<-release
+		if err := r.Context().Err(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+	}))
+
+	serve := func(method string) <-chan int {
+		code := make(chan int, 1)
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), method, "/", nil))
+			code <- rec.Code
+		}()
+		return code
+	}
+	stream := serve(http.MethodGet)
+	call := serve(http.MethodPost)
+
+	startShutdown()
+	select {
+	case <-stream:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GET stream still open after shutdown started")
+	}
+
+	// The tool call started before the shutdown finishes with its context intact
+	close(release)
+	if code := <-call; code != http.StatusOK {
+		t.Errorf("POST during shutdown: got status %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "sequential_thinking"
+
+var (
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tool_calls_total",
+		Help:      "Number of tool calls by tool name and result (success or error).",
+	}, []string{"tool", "result"})
+
+	toolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Latency of the tool handlers by tool name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"tool"})
+)
+
+// registerMetrics registers the tool call metrics and the thinking state gauges with reg.
+//
+// The gauges report the thought and branch counts returned by counts.
+func registerMetrics(reg prometheus.Registerer, counts func() (thoughts, branches int)) error {
+	thoughts := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "thoughts",
+		Help:      "Number of recorded thoughts, including revisions and branch thoughts.",
+	}, func() float64 {
+		thoughts, _ := counts()
+		return float64(thoughts)
+	})
+	branches := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "branches",
+		Help:      "Number of thought branches.",
+	}, func() float64 {
+		_, branches := counts()
+		return float64(branches)
+	})
+
+	for _, c := range []prometheus.Collector{toolCalls, toolCallDuration, thoughts, branches} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instrumentToolCalls is a receiving middleware that records the count and latency of each tool call,
+// so that every registered tool gets metrics without instrumenting its handler.
+func instrumentToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+		toolCallDuration.WithLabelValues(call.Params.Name).Observe(time.Since(start).Seconds())
+
+		outcome := "success"
+		if result, ok := res.(*mcp.CallToolResult); err != nil || (ok && result != nil && result.IsError) {
+			outcome = "error"
+		}
+		toolCalls.WithLabelValues(call.Params.Name, outcome).Inc()
+
+		return res, err
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// analysisApproaches maps the approach argument of the structured_analysis prompt to how the thoughts should be structured.
+var analysisApproaches = map[string]string{
+	"pros-cons": `Structure the thoughts as a pros and cons analysis:
+1. Restate the problem and the options being considered.
+2. Use one thought per option to list its pros and cons.
+3. Weigh the options against each other, revising earlier thoughts if new trade-offs come up.
+4. Conclude with the recommended option and why.`,
+	"first-principles": `Structure the thoughts as a first principles analysis:
+1. Restate the problem and question every assumption in it.
+2. Break the problem down into its fundamental truths, one per thought.
+3. Rebuild a solution from those truths only, branching to explore alternatives.
+4. Conclude with the solution and the principles it rests on.`,
+	"five-whys": `Structure the thoughts as a five whys analysis:
+1. Restate the problem as an observed symptom.
+2. Ask "why?" of the previous answer, one thought per why, for at least five thoughts.
+3. Branch when a why has more than one plausible answer.
+4. Conclude with the root cause and how to address it.`,
+}
+
+// defaultAnalysisApproach is the structure used when the structured_analysis prompt gets no approach.
+const defaultAnalysisApproach = `Structure the thoughts freely:
+1. Restate the problem and what a good answer looks like.
+2. Break it down step by step, revising or branching whenever your understanding changes.
+3. Conclude with a verified answer.`
+
+// structuredAnalysisPrompt returns the structured_analysis prompt.
+func structuredAnalysisPrompt() *mcp.Prompt {
+	approaches := slices.Sorted(maps.Keys(analysisApproaches))
+
+	return &mcp.Prompt{
+		Name:        "structured_analysis",
+		Description: "Analyze a problem step by step with the sequentialthinking tool, following an optional analysis structure",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "problem",
+				Description: "the problem to analyze",
+				Required:    true,
+			},
+			{
+				Name:        "approach",
+				Description: "structure of the analysis: " + strings.Join(approaches, ", "),
+			},
+		},
+	}
+}
+
+// StructuredAnalysis renders the structured_analysis prompt.
+func StructuredAnalysis(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := request.Params.Arguments
+	problem := strings.TrimSpace(args["problem"])
+	if problem == "" {
+		return nil, errors.New("invalid problem: must not be empty")
+	}
+	structure := defaultAnalysisApproach
+	if approach := args["approach"]; approach != "" {
+		var ok bool
+		structure, ok = analysisApproaches[approach]
+		if !ok {
+			return nil, fmt.Errorf("invalid approach %q: must be one of %s", approach, strings.Join(slices.Sorted(maps.Keys(analysisApproaches)), ", "))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Analyze the following problem step by step with the sequentialthinking tool.\n\nProblem: %s\n\n", problem)
+	sb.WriteString(structure)
+	sb.WriteString(`
+
+Call the sequentialthinking tool once per thought, starting with thoughtNumber 1 and an estimate of totalThoughts.
+Keep calling it with nextThoughtNeeded set to true until the analysis is complete, then set it to false and give the final answer.
+Use search_thoughts to look up earlier thoughts instead of repeating them.`)
+
+	return &mcp.GetPromptResult{
+		Description: "Structured analysis of: " + problem,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role: "user",
+				Content: &mcp.TextContent{
+					Text: sb.String(),
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"cmp"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStructuredAnalysisPrompt(t *testing.T) {
+	srv, err := newMCPServer(slog.New(slog.DiscardHandler), newTestServer(t, nil))
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	cs := connectClient(t, srv)
+
+	tools, err := cs.ListTools(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+
+	for _, approach := range []string{"", "pros-cons", "first-principles", "five-whys"} {
+		t.Run(cmp.Or(approach, "default"), func(t *testing.T) {
+			res, err := cs.GetPrompt(t.Context(), &mcp.GetPromptParams{
+				Name: "structured_analysis",
+				Arguments: map[string]string{
+					"problem":  "choose a queue for the job scheduler",
+					"approach": approach,
+				},
+			})
+			if err != nil {
+				t.Fatalf("GetPrompt: %v", err)
+			}
+			if len(res.Messages) != 1 {
+				t.Fatalf("got %d messages, want 1", len(res.Messages))
+			}
+			text, ok := res.Messages[0].Content.(*mcp.TextContent)
+			if !ok {
+				t.Fatalf("got %T message content, want *mcp.TextContent", res.Messages[0].Content)
+			}
+			if !strings.Contains(text.Text, "choose a queue for the job scheduler") {
+				t.Errorf("prompt doesn't contain the problem:\n%s", text.Text)
+			}
+			// The prompt must name the tools the server actually registers
+			for _, tool := range tools.Tools {
+				if !strings.Contains(text.Text, tool.Name) {
+					t.Errorf("prompt doesn't reference the %s tool:\n%s", tool.Name, text.Text)
+				}
+			}
+			if approach != "" && !strings.Contains(text.Text, analysisApproaches[approach]) {
+				t.Errorf("prompt doesn't contain the %s structure:\n%s", approach, text.Text)
+			}
+		})
+	}
+
+	errTests := map[string]struct {
+		args    map[string]string
+		wantErr string
+	}{
+		"UnknownApproach": {
+			args:    map[string]string{"problem": "problem", "approach": "six-hats"},
+			wantErr: `invalid approach "six-hats": must be one of first-principles, five-whys, pros-cons`,
+		},
+		"BlankProblem": {
+			args:    map[string]string{"problem": " \t"},
+			wantErr: "invalid problem: must not be empty",
+		},
+	}
+	for name, tt := range errTests {
+		t.Run(name, func(t *testing.T) {
+			_, err := cs.GetPrompt(t.Context(), &mcp.GetPromptParams{
+				Name:      "structured_analysis",
+				Arguments: tt.args,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetPrompt: got error %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
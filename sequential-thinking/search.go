@@ -0,0 +1,196 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultSearchLimit is the number of matches returned by search_thoughts when no limit is given.
+	defaultSearchLimit = 20
+
+	// snippetContext is the number of bytes of context shown on each side of a match.
+	snippetContext = 40
+)
+
+// SearchThoughtsArgs represents the input of the search_thoughts tool.
+type SearchThoughtsArgs struct {
+	Query    string `json:"query" jsonschema:"text to search for in the recorded thoughts, case-insensitive"`
+	Regex    bool   `json:"regex,omitzero" jsonschema:"treat query as a regular expression instead of a plain substring"`
+	BranchID string `json:"branchId,omitzero" jsonschema:"only search the thoughts of this branch"`
+	Limit    int    `json:"limit,omitzero" jsonschema:"maximum number of matches to return (default 20)"`
+}
+
+// ThoughtMatch is a recorded thought matching a search.
+type ThoughtMatch struct {
+	Index         int    `json:"index" jsonschema:"1-based position of the thought in the thought history"`
+	ThoughtNumber int    `json:"thoughtNumber" jsonschema:"thought number of the matching thought"`
+	BranchID      string `json:"branchId,omitzero" jsonschema:"branch of the matching thought, if any"`
+	Snippet       string `json:"snippet" jsonschema:"excerpt around the match, with the match wrapped in **"`
+	SessionID     string `json:"sessionId,omitzero" jsonschema:"MCP session of the client that recorded the thought, in the admin view of isolated clients"`
+}
+
+// SearchThoughtsResult is the structured result of the search_thoughts tool.
+type SearchThoughtsResult struct {
+	Matches []ThoughtMatch `json:"matches" jsonschema:"matching thoughts in history order"`
+}
+
+// SearchThoughts searches the recorded thoughts for args.Query.
+//
+// It is a linear scan over the thought history, which is fine at in-memory scale.
+func (s *SequentialThinkingServer) SearchThoughts(ctx context.Context, request *mcp.CallToolRequest, args SearchThoughtsArgs) (*mcp.CallToolResult, *SearchThoughtsResult, error) {
+	re, limit, err := compileSearch(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &SearchThoughtsResult{
+		Matches: s.search(make([]ThoughtMatch, 0), re, args.BranchID, limit, ""),
+	}
+
+	return searchResult(args.Query, result), result, nil
+}
+
+// compileSearch validates args and returns the case-insensitive pattern of its query and the match limit.
+func compileSearch(args SearchThoughtsArgs) (*regexp.Regexp, int, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, 0, errors.New("invalid query: must not be empty")
+	}
+	if args.Limit < 0 {
+		return nil, 0, errors.New("invalid limit: must be a number >= 0")
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	pattern := args.Query
+	if !args.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid query: %w", err)
+	}
+
+	return re, limit, nil
+}
+
+// search appends the recorded thoughts matching re, of branchID if not empty, to matches until it holds limit matches.
+// The matches are tagged with sessionID.
+func (s *SequentialThinkingServer) search(matches []ThoughtMatch, re *regexp.Regexp, branchID string, limit int, sessionID string) []ThoughtMatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, thought := range s.thoughtHistory {
+		if len(matches) >= limit {
+			break
+		}
+		if branchID != "" && thought.BranchId != branchID {
+			continue
+		}
+		loc := firstMatch(re, thought.Thought)
+		if loc == nil {
+			continue
+		}
+		matches = append(matches, ThoughtMatch{
+			Index:         i + 1,
+			ThoughtNumber: thought.ThoughtNumber,
+			BranchID:      thought.BranchId,
+			Snippet:       snippet(thought.Thought, loc[0], loc[1]),
+			SessionID:     sessionID,
+		})
+	}
+
+	return matches
+}
+
+// firstMatch returns the location of the first non-empty match of re in text, or nil if there is none.
+//
+// Empty matches, of patterns like "x*" or `\b`, are skipped since they have nothing to highlight.
+func firstMatch(re *regexp.Regexp, text string) []int {
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] < loc[1] {
+			return loc
+		}
+	}
+
+	return nil
+}
+
+// searchResult renders the matches of result for query as the text of the tool result.
+func searchResult(query string, result *SearchThoughtsResult) *mcp.CallToolResult {
+	var sb strings.Builder
+	if len(result.Matches) == 0 {
+		fmt.Fprintf(&sb, "no thoughts match %q", query)
+	}
+	for i, match := range result.Matches {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if match.SessionID != "" {
+			fmt.Fprintf(&sb, "session %s / ", match.SessionID)
+		}
+		if match.BranchID != "" {
+			fmt.Fprintf(&sb, "branch %s / ", match.BranchID)
+		}
+		fmt.Fprintf(&sb, "thought %d: %s", match.ThoughtNumber, match.Snippet)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}
+}
+
+// snippet returns the excerpt of text around text[start:end], with the match wrapped in "**".
+func snippet(text string, start, end int) string {
+	from := max(start-snippetContext, 0)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	to := min(end+snippetContext, len(text))
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	sb.WriteString(text[from:start])
+	sb.WriteString("**")
+	sb.WriteString(text[start:end])
+	sb.WriteString("**")
+	sb.WriteString(text[end:to])
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+
+	return strings.ReplaceAll(sb.String(), "\n", " ")
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newSearchServer creates a server with a few recorded thoughts, one of them on the "alt" branch.
+func newSearchServer(t *testing.T) *SequentialThinkingServer {
+	t.Helper()
+
+	s := newTestServer(t, nil)
+	thoughts := []ThoughtData{
+		{Thought: "The cache Invalidation protocol is tricky", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 3},
+		{Thought: "Retry with exponential backoff", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 3},
+		{Thought: "cache warming on the alt path", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 3, BranchFromThought: 1, BranchId: "alt"},
+		{Thought: "cache eviction uses LRU", NextThoughtNeeded: false, ThoughtNumber: 3, TotalThoughts: 3},
+	}
+	for _, thought := range thoughts {
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			t.Fatalf("ProcessThought(%q): %v", thought.Thought, err)
+		}
+	}
+
+	return s
+}
+
+func TestSearchThoughts(t *testing.T) {
+	var (
+		invalidation = ThoughtMatch{Index: 1, ThoughtNumber: 1, Snippet: "The cache **Invalidation** protocol is tricky"}
+		cache1       = ThoughtMatch{Index: 1, ThoughtNumber: 1, Snippet: "The **cache** Invalidation protocol is tricky"}
+		cacheAlt     = ThoughtMatch{Index: 3, ThoughtNumber: 2, BranchID: "alt", Snippet: "**cache** warming on the alt path"}
+		cache3       = ThoughtMatch{Index: 4, ThoughtNumber: 3, Snippet: "**cache** eviction uses LRU"}
+	)
+	tests := map[string]struct {
+		args SearchThoughtsArgs
+		want []ThoughtMatch
+	}{
+		"CaseInsensitiveSubstring": {
+			args: SearchThoughtsArgs{Query: "INVALIDATION"},
+			want: []ThoughtMatch{invalidation},
+		},
+		"SubstringIsLiteral": {
+			args: SearchThoughtsArgs{Query: "cache.*LRU"},
+			want: []ThoughtMatch{},
+		},
+		"Regex": {
+			args: SearchThoughtsArgs{Query: `evict\w+|WARM\w+`, Regex: true},
+			want: []ThoughtMatch{
+				{Index: 3, ThoughtNumber: 2, BranchID: "alt", Snippet: "cache **warming** on the alt path"},
+				{Index: 4, ThoughtNumber: 3, Snippet: "cache **eviction** uses LRU"},
+			},
+		},
+		"Branch": {
+			args: SearchThoughtsArgs{Query: "cache", BranchID: "alt"},
+			want: []ThoughtMatch{cacheAlt},
+		},
+		"UnknownBranch": {
+			args: SearchThoughtsArgs{Query: "cache", BranchID: "other"},
+			want: []ThoughtMatch{},
+		},
+		"DefaultLimit": {
+			args: SearchThoughtsArgs{Query: "cache"},
+			want: []ThoughtMatch{cache1, cacheAlt, cache3},
+		},
+		"Limit": {
+			args: SearchThoughtsArgs{Query: "cache", Limit: 2},
+			want: []ThoughtMatch{cache1, cacheAlt},
+		},
+		"EmptyMatchesSkipped": {
+			// x* matches the empty text before each thought, the first non-empty match is highlighted instead
+			args: SearchThoughtsArgs{Query: "x*", Regex: true},
+			want: []ThoughtMatch{{Index: 2, ThoughtNumber: 2, Snippet: "Retry with e**x**ponential backoff"}},
+		},
+		"OnlyEmptyMatches": {
+			args: SearchThoughtsArgs{Query: `\b`, Regex: true},
+			want: []ThoughtMatch{},
+		},
+	}
+	s := newSearchServer(t)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, res, err := s.SearchThoughts(t.Context(), nil, tt.args)
+			if err != nil {
+				t.Fatalf("SearchThoughts: %v", err)
+			}
+			if !slices.Equal(res.Matches, tt.want) {
+				t.Errorf("SearchThoughts(%+v) =\n%+v\nwant\n%+v", tt.args, res.Matches, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchThoughtsToolErrors(t *testing.T) {
+	srv, err := newMCPServer(slog.New(slog.DiscardHandler), newSearchServer(t))
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	cs := connectClient(t, srv)
+
+	tests := map[string]struct {
+		args    map[string]any
+		wantErr string
+	}{
+		"InvalidRegex": {
+			args:    map[string]any{"query": "(", "regex": true},
+			wantErr: "invalid query: error parsing regexp: missing closing ): `(?i)(`",
+		},
+		"BlankQuery": {
+			args:    map[string]any{"query": " "},
+			wantErr: "invalid query: must not be empty",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "search_thoughts", Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("CallTool: %v", err)
+			}
+			if !res.IsError {
+				t.Fatalf("search_thoughts succeeded, want the tool error %q", tt.wantErr)
+			}
+			if got := res.Content[0].(*mcp.TextContent).Text; got != tt.wantErr {
+				t.Errorf("search_thoughts error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	// The context around the match is snippetContext bytes, widened to whole runes
+	wide := strings.Repeat("日", 20)
+	tests := map[string]struct {
+		text       string
+		start, end int
+		want       string
+	}{
+		"Short": {
+			text:  "a short match here",
+			start: 8,
+			end:   13,
+			want:  "a short **match** here",
+		},
+		"Newlines": {
+			text:  "first line\nmatch\nlast line",
+			start: 11,
+			end:   16,
+			want:  "first line **match** last line",
+		},
+		"Trimmed": {
+			text:  strings.Repeat("a", 50) + "match" + strings.Repeat("b", 50),
+			start: 50,
+			end:   55,
+			want:  "…" + strings.Repeat("a", snippetContext) + "**match**" + strings.Repeat("b", snippetContext) + "…",
+		},
+		"TrimmedOnRuneBoundaries": {
+			text:  wide + "match" + wide,
+			start: len(wide),
+			end:   len(wide) + len("match"),
+			want:  "…" + strings.Repeat("日", 14) + "**match**" + strings.Repeat("日", 14) + "…",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := snippet(tt.text, tt.start, tt.end)
+			if !utf8.ValidString(got) {
+				t.Errorf("snippet = %q, not valid UTF-8", got)
+			}
+			if got != tt.want {
+				t.Errorf("snippet = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/bytedance/gg/gson"
+	"github.com/bytedance/sonic"
+)
+
+// stateFileName is the name of the file the thinking state is persisted to within the state directory.
+const stateFileName = "sequential-thinking.json"
+
+// thinkingState is the persisted form of the [SequentialThinkingServer] state.
+type thinkingState struct {
+	ThoughtHistory []ThoughtData            `json:"thoughtHistory"`
+	Branches       map[string][]ThoughtData `json:"branches"`
+}
+
+// snapshot returns a copy of the thinking state that stays valid after s.mu is released.
+//
+// Recorded thoughts are never modified in place, so only the branch map needs to be copied.
+// The caller must hold s.mu.
+func (s *SequentialThinkingServer) snapshot() thinkingState {
+	return thinkingState{
+		ThoughtHistory: s.thoughtHistory,
+		Branches:       maps.Clone(s.branches),
+	}
+}
+
+// writeState writes state as JSON to w.
+func writeState(w io.Writer, state thinkingState) error {
+	data, err := gson.MarshalIndentBy(sonic.ConfigStd, state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	return nil
+}
+
+// Save writes a JSON snapshot of the thinking state to w, in the form [SequentialThinkingServer.Load] reads.
+func (s *SequentialThinkingServer) Save(w io.Writer) error {
+	s.mu.Lock()
+	state := s.snapshot()
+	s.mu.Unlock()
+
+	return writeState(w, state)
+}
+
+// Load replaces the thinking state with the JSON snapshot read from r.
+//
+// Malformed thoughts are skipped with a logged warning rather than failing the whole load.
+func (s *SequentialThinkingServer) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read state: %w", err)
+	}
+	state, err := gson.UnmarshalBy[thinkingState](sonic.ConfigStd, data)
+	if err != nil {
+		return fmt.Errorf("unmarshal state: %w", err)
+	}
+
+	history := s.validThoughts("", state.ThoughtHistory)
+	branches := make(map[string][]ThoughtData, len(state.Branches))
+	for branchID, thoughts := range state.Branches {
+		if branchID == "" {
+			slog.Warn("skip branch with empty ID", slog.Int("thoughts", len(thoughts)))
+			continue
+		}
+		branches[branchID] = s.validThoughts(branchID, thoughts)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.thoughtHistory = history
+	s.branches = branches
+
+	return nil
+}
+
+// validThoughts returns the thoughts that hold the invariants of a recorded thought, logging a warning for each skipped thought.
+func (s *SequentialThinkingServer) validThoughts(branchID string, thoughts []ThoughtData) []ThoughtData {
+	valid := make([]ThoughtData, 0, len(thoughts))
+	for i, thought := range thoughts {
+		if err := validateRecordedThought(thought); err != nil {
+			slog.Warn("skip malformed thought", slog.String("branchId", branchID), slog.Int("index", i), slog.Any("error", err))
+			continue
+		}
+		valid = append(valid, thought)
+	}
+
+	return valid
+}
+
+// readStateFile reads the state file at path. A missing file is not an error and returns nil data.
+func readStateFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	return data, nil
+}
+
+// LoadFile loads the thinking state from the JSON snapshot at path.
+//
+// A missing file is not an error. An invalid file is an error, so that the snapshot written on shutdown doesn't overwrite it.
+func (s *SequentialThinkingServer) LoadFile(path string) error {
+	data, err := readStateFile(path)
+	if err != nil || data == nil {
+		return err
+	}
+
+	if err := s.Load(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("load state file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// SaveFile atomically writes a JSON snapshot of the thinking state to path.
+func (s *SequentialThinkingServer) SaveFile(path string) error {
+	s.mu.Lock()
+	state := s.snapshot()
+	s.mu.Unlock()
+
+	return writeStateFile(path, state)
+}
+
+// writeStateFile atomically writes state as JSON to path.
+//
+// The state is written to a temporary file first and renamed into place so that a crash mid-write never corrupts the previous state.
+func writeStateFile(path string, state thinkingState) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeState(tmp, state); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temporary state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temporary state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename state file: %w", err)
+	}
+
+	return nil
+}
+
+// loadState loads the persisted thinking state from the state directory.
+//
+// An invalid state file is renamed aside with an ".invalid" suffix, so that the next saved thought doesn't overwrite it.
+func (s *SequentialThinkingServer) loadState() error {
+	if err := os.MkdirAll(s.stateDir, 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	path := filepath.Join(s.stateDir, stateFileName)
+	data, err := readStateFile(path)
+	if err != nil || data == nil {
+		return err
+	}
+
+	if err := s.Load(bytes.NewReader(data)); err != nil {
+		invalid := path + ".invalid"
+		if renameErr := os.Rename(path, invalid); renameErr != nil {
+			return fmt.Errorf("move invalid state file aside: %w", errors.Join(err, renameErr))
+		}
+		slog.Warn("moved invalid state file aside", slog.String("path", invalid), slog.Any("error", err))
+	}
+
+	return nil
+}
+
+// saveState atomically writes state, taken as the stateVersion version of the thinking state, to the state directory.
+//
+// It is called without holding s.mu so that the disk I/O doesn't block other thoughts.
+// Writes are serialized, and a state older than the last written one is dropped so that a slow write can't roll the file back.
+func (s *SequentialThinkingServer) saveState(version uint64, state thinkingState) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if version <= s.savedVersion {
+		return nil
+	}
+	if err := writeStateFile(filepath.Join(s.stateDir, stateFileName), state); err != nil {
+		return err
+	}
+	s.savedVersion = version
+
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	s := newTestServer(t, nil)
+	thoughts := []ThoughtData{
+		{Thought: "first", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 3, Confidence: ptr(0.5)},
+		{Thought: "second", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 3},
+		{Thought: "fork", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 3, BranchFromThought: 1, BranchId: "alt"},
+		{Thought: "revised", NextThoughtNeeded: false, ThoughtNumber: 3, TotalThoughts: 3, IsRevision: true, RevisesThought: 2},
+	}
+	for _, thought := range thoughts {
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			t.Fatalf("ProcessThought(%q): %v", thought.Thought, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded := newTestServer(t, nil)
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	assertState(t, loaded, s.thoughtHistory, s.branches)
+}
+
+func TestLoadSkipsMalformedThoughts(t *testing.T) {
+	valid := ThoughtData{Thought: "valid", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 2}
+	state := map[string]any{
+		"thoughtHistory": []any{
+			valid,
+			map[string]any{"thought": "no number", "nextThoughtNeeded": true, "totalThoughts": 2},
+			map[string]any{"thought": "", "nextThoughtNeeded": true, "thoughtNumber": 2, "totalThoughts": 2},
+			map[string]any{"thought": "too confident", "nextThoughtNeeded": true, "thoughtNumber": 2, "totalThoughts": 2, "confidence": 2},
+		},
+		"branches": map[string]any{
+			"alt": []any{
+				valid,
+				map[string]any{"thought": "bad fork", "nextThoughtNeeded": true, "thoughtNumber": 2, "totalThoughts": 2, "branchFromThought": -1},
+			},
+			"": []any{valid},
+		},
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer(t, nil)
+	if err := s.Load(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	wantHistory := []ThoughtData{valid}
+	wantBranches := map[string][]ThoughtData{"alt": {valid}}
+	assertState(t, s, wantHistory, wantBranches)
+
+	// What was kept saves and loads back unchanged
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	reloaded := newTestServer(t, nil)
+	if err := reloaded.Load(&buf); err != nil {
+		t.Fatalf("Load of the saved state: %v", err)
+	}
+	assertState(t, reloaded, wantHistory, wantBranches)
+}
+
+// assertState checks the thinking state of s against the wanted history and branches.
+func assertState(t *testing.T, s *SequentialThinkingServer, history []ThoughtData, branches map[string][]ThoughtData) {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.EqualFunc(s.thoughtHistory, history, equalThought) {
+		t.Errorf("thought history = %+v, want %+v", s.thoughtHistory, history)
+	}
+	if !maps.EqualFunc(s.branches, branches, func(a, b []ThoughtData) bool { return slices.EqualFunc(a, b, equalThought) }) {
+		t.Errorf("branches = %+v, want %+v", s.branches, branches)
+	}
+}
+
+// equalThought reports whether a and b are the same thought, comparing the confidence by value.
+func equalThought(a, b ThoughtData) bool {
+	if !equalPtr(a.Confidence, b.Confidence) {
+		return false
+	}
+	a.Confidence, b.Confidence = nil, nil
+	return a == b
+}
+
+func TestStateDirReload(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestServer(t, &Options{StateDir: dir})
+	thoughts := []ThoughtData{
+		{Thought: "first", NextThoughtNeeded: true, ThoughtNumber: 1, TotalThoughts: 2},
+		{Thought: "fork", NextThoughtNeeded: true, ThoughtNumber: 2, TotalThoughts: 2, BranchFromThought: 1, BranchId: "alt"},
+		{Thought: "second", NextThoughtNeeded: false, ThoughtNumber: 2, TotalThoughts: 2},
+	}
+	for _, thought := range thoughts {
+		if _, _, err := s.ProcessThought(t.Context(), nil, thought); err != nil {
+			t.Fatalf("ProcessThought(%q): %v", thought.Thought, err)
+		}
+	}
+
+	reloaded := newTestServer(t, &Options{StateDir: dir})
+	assertState(t, reloaded, thoughts, map[string][]ThoughtData{"alt": {thoughts[1]}})
+}
+
+func TestStateDirInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, stateFileName)
+	const corrupt = `{"thoughtHistory": [`
+	if err := os.WriteFile(path, []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer(t, &Options{StateDir: dir})
+	assertState(t, s, []ThoughtData{}, map[string][]ThoughtData{})
+
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("state file still in place after loading it failed: %v", err)
+	}
+	data, err := os.ReadFile(path + ".invalid")
+	if err != nil {
+		t.Fatalf("read the invalid state file moved aside: %v", err)
+	}
+	if string(data) != corrupt {
+		t.Errorf("invalid state file = %q, want %q", data, corrupt)
+	}
+}
+
+func TestSaveStateKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestServer(t, &Options{StateDir: dir})
+
+	// stateOf returns the state of version v, which holds v thoughts
+	stateOf := func(v int) thinkingState {
+		state := thinkingState{ThoughtHistory: make([]ThoughtData, 0, v), Branches: map[string][]ThoughtData{}}
+		for i := 1; i <= v; i++ {
+			state.ThoughtHistory = append(state.ThoughtHistory, ThoughtData{Thought: fmt.Sprintf("thought %d", i), ThoughtNumber: i, TotalThoughts: v})
+		}
+		return state
+	}
+	savedThoughts := func() int {
+		t.Helper()
+		loaded := newTestServer(t, nil)
+		if err := loaded.LoadFile(filepath.Join(dir, stateFileName)); err != nil {
+			t.Fatalf("LoadFile: %v", err)
+		}
+		return len(loaded.thoughtHistory)
+	}
+
+	// A write that loses the race to a newer one is dropped
+	if err := s.saveState(2, stateOf(2)); err != nil {
+		t.Fatalf("saveState(2): %v", err)
+	}
+	if err := s.saveState(1, stateOf(1)); err != nil {
+		t.Fatalf("saveState(1): %v", err)
+	}
+	if got := savedThoughts(); got != 2 {
+		t.Fatalf("saved state has %d thoughts after an older write, want 2", got)
+	}
+
+	const versions = 50
+	var wg sync.WaitGroup
+	for v := 3; v <= versions; v++ {
+		wg.Go(func() {
+			if err := s.saveState(uint64(v), stateOf(v)); err != nil {
+				t.Errorf("saveState(%d): %v", v, err)
+			}
+		})
+	}
+	wg.Wait()
+	if got := savedThoughts(); got != versions {
+		t.Errorf("saved state has %d thoughts after concurrent writes, want the newest %d", got, versions)
+	}
+}
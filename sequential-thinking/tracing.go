@@ -0,0 +1,143 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/bytedance/gg/gson"
+	"github.com/bytedance/sonic"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts a span for each thinking tool call. Its spans are dropped until initTracer installs an exporting provider.
+var tracer = otel.Tracer("github.com/zchee/mcp-servers/sequential-thinking")
+
+// initTracer sets up the global tracer provider to export spans over OTLP/HTTP.
+//
+// Tracing is enabled only when OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, and it returns a nil provider otherwise.
+// Spans are never written to stdout, which would corrupt the JSON-RPC stream in stdio mode.
+func initTracer(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return nil, nil
+	}
+
+	// otlptracehttp reads the endpoint, headers and TLS settings from the OTEL_EXPORTER_OTLP_* environment variables
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName("sequential-thinking"),
+			semconv.ServiceVersion(version),
+		),
+		resource.WithSchemaURL(semconv.SchemaURL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(r),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
+
+	return tp, nil
+}
+
+// traceToolCalls is a receiving middleware that wraps each tool call in a span named after the tool.
+//
+// The span continues the trace of the caller, if any. It records the MCP session ID and, for thoughts, the thought number and whether it is a revision or a branch thought.
+func traceToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		ctx, span := tracer.Start(extractTraceContext(ctx, call), method+" "+call.Params.Name)
+		defer span.End()
+
+		span.SetAttributes(attribute.String("mcp.tool.name", call.Params.Name))
+		// Only the HTTP transport has session IDs
+		if call.Session != nil && call.Session.ID() != "" {
+			span.SetAttributes(attribute.String("mcp.session.id", call.Session.ID()))
+		}
+		// Arguments that aren't a thought, like those of search_thoughts, get no thought attributes; ProcessThought rejects invalid ones
+		if thought, err := gson.UnmarshalBy[ThoughtData](sonic.ConfigStd, call.Params.Arguments); err == nil && thought.ThoughtNumber > 0 {
+			span.SetAttributes(
+				attribute.Int("thought.number", thought.ThoughtNumber),
+				attribute.Bool("thought.is_revision", thought.IsRevision),
+				attribute.Bool("thought.is_branch", thought.BranchFromThought > 0),
+			)
+			if thought.BranchId != "" {
+				span.SetAttributes(attribute.String("thought.branch_id", thought.BranchId))
+			}
+		}
+
+		res, err := next(ctx, method, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result, ok := res.(*mcp.CallToolResult); ok && result != nil && result.IsError {
+			span.SetStatus(codes.Error, "tool call failed")
+		}
+
+		return res, err
+	}
+}
+
+// extractTraceContext returns ctx with the trace context propagated by the caller of call, so that the tool call span joins the caller's trace.
+//
+// The W3C traceparent and baggage are read from the HTTP request headers in HTTP mode, and otherwise from the _meta of the request params,
+// which is the only carrier available over stdio.
+func extractTraceContext(ctx context.Context, call *mcp.CallToolRequest) context.Context {
+	propagator := otel.GetTextMapPropagator()
+	if call.Extra != nil && call.Extra.Header != nil {
+		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(call.Extra.Header))
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			return ctx
+		}
+	}
+
+	carrier := propagation.MapCarrier{}
+	for key, value := range call.Params.GetMeta() {
+		if value, ok := value.(string); ok {
+			carrier[key] = value
+		}
+	}
+	return propagator.Extract(ctx, carrier)
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExtractTraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	const (
+		traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	)
+	tests := map[string]struct {
+		call        *mcp.CallToolRequest
+		wantTraceID string
+	}{
+		"HTTPHeader": {
+			call: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{},
+				Extra:  &mcp.RequestExtra{Header: http.Header{"Traceparent": {traceparent}}},
+			},
+			wantTraceID: traceID,
+		},
+		"Meta": {
+			call: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Meta: mcp.Meta{"traceparent": traceparent}},
+			},
+			wantTraceID: traceID,
+		},
+		"None": {
+			call: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{},
+				Extra:  &mcp.RequestExtra{Header: http.Header{}},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sc := trace.SpanContextFromContext(extractTraceContext(t.Context(), tt.call))
+			if tt.wantTraceID == "" {
+				if sc.IsValid() {
+					t.Errorf("got span context %v, want none", sc)
+				}
+				return
+			}
+			if got := sc.TraceID().String(); got != tt.wantTraceID || !sc.IsRemote() {
+				t.Errorf("got trace ID %s (remote %t), want remote trace ID %s", got, sc.IsRemote(), tt.wantTraceID)
+			}
+		})
+	}
+}
@@ -19,12 +19,20 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -33,21 +41,100 @@ import (
 )
 
 const (
-	envWeaviateURL       = "WEAVIATE_URL"
-	envWeaviateGRPCURL   = "WEAVIATE_GRPC_URL"
-	envWeaviateAPIKey    = "WEAVIATE_API_KEY"
-	envHuggingFaceAPIKey = "HUGGINGFACE_API_KEY"
-	envVoyageAIAPIKey    = "VOYAGEAI_API_KEY"
-	envCohereAPIKey      = "COHERE_API_KEY"
-	envJinaAIAPIKey      = "JINAAI_API_KEY"
+	envWeaviateURL          = "WEAVIATE_URL"
+	envWeaviateScheme       = "WEAVIATE_SCHEME"
+	envWeaviateGRPCURL      = "WEAVIATE_GRPC_URL"
+	envWeaviateGRPCSecured  = "WEAVIATE_GRPC_SECURED"
+	envWeaviateAPIKey       = "WEAVIATE_API_KEY"
+	envWeaviateClientSecret = "WEAVIATE_CLIENT_SECRET"
+	envWeaviateOIDCScopes   = "WEAVIATE_OIDC_SCOPES"
+	envHuggingFaceAPIKey    = "HUGGINGFACE_API_KEY"
+	envVoyageAIAPIKey       = "VOYAGEAI_API_KEY"
+	envCohereAPIKey         = "COHERE_API_KEY"
+	envJinaAIAPIKey         = "JINAAI_API_KEY"
+	envMaxQueryLimit        = "WEAVIATE_MAX_QUERY_LIMIT"
+
+	envOTelTracesExporter             = "OTEL_TRACES_EXPORTER"
+	envOTelExporterOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envOTelExporterOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// shutdownTimeout is how long the HTTP server waits for in-flight requests to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// closeStreamsOn cancels the SSE streams opened by GET requests to next once done is canceled.
+//
+// A stream keeps its connection active for as long as the client listens, so without this a graceful shutdown with a client
+// connected would always run into shutdownTimeout. Tool calls arrive as POST requests and are left to complete.
+func closeStreamsOn(done context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			defer context.AfterFunc(done, cancel)()
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+var (
+	httpAddr string
+	trace    bool
 )
 
+func init() {
+	flag.StringVar(&httpAddr, "http", "", "if set, use streamable HTTP at this address, instead of stdin/stdout")
+	flag.BoolVar(&trace, "trace", false, "enable OpenTelemetry tracing. Spans are exported over OTLP/HTTP if "+envOTelExporterOTLPEndpoint+" is set, otherwise printed to stdout (stderr in stdio mode)")
+}
+
+// tracingEnabled reports whether tracing is enabled by the -trace flag or the OTEL_TRACES_EXPORTER environment variable.
+func tracingEnabled() bool {
+	switch os.Getenv(envOTelTracesExporter) {
+	case "":
+		return trace
+	case "none":
+		return false
+	default:
+		return true
+	}
+}
+
+// newTraceExporter creates the span exporter selected by the OTEL_TRACES_EXPORTER and OTEL_EXPORTER_OTLP_ENDPOINT environment variables.
+//
+// The console exporter writes to stderr in stdio mode so that it doesn't corrupt the MCP stream on stdout.
+func newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	name := os.Getenv(envOTelTracesExporter)
+	if name == "" {
+		name = "console"
+		if os.Getenv(envOTelExporterOTLPEndpoint) != "" || os.Getenv(envOTelExporterOTLPTracesEndpoint) != "" {
+			name = "otlp"
+		}
+	}
+
+	switch name {
+	case "otlp":
+		// otlptracehttp reads the endpoint, headers and TLS settings from the OTEL_EXPORTER_OTLP_* environment variables
+		return otlptracehttp.New(ctx)
+
+	case "console":
+		var w io.Writer = os.Stdout
+		if httpAddr == "" {
+			w = os.Stderr
+		}
+		return stdouttrace.New(
+			stdouttrace.WithWriter(w),
+			stdouttrace.WithPrettyPrint(),
+			stdouttrace.WithoutTimestamps(),
+		)
+
+	default:
+		return nil, fmt.Errorf("unsupported %s %q: must be otlp, console or none", envOTelTracesExporter, name)
+	}
+}
+
 func initTracer(ctx context.Context) (*sdktrace.TracerProvider, error) {
-	exporter, err := stdouttrace.New(
-		stdouttrace.WithWriter(os.Stdout),
-		stdouttrace.WithPrettyPrint(),
-		stdouttrace.WithoutTimestamps(),
-	)
+	exporter, err := newTraceExporter(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -81,18 +168,25 @@ func initTracer(ctx context.Context) (*sdktrace.TracerProvider, error) {
 }
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// tp, err := initTracer(ctx)
-	// if err != nil {
-	// 	log.Fatal(err)
-	// }
-	// defer func() {
-	// 	if err := tp.Shutdown(context.Background()); err != nil {
-	// 		log.Printf("Error shutting down tracer provider: %v", err)
-	// 	}
-	// }()
+	if tracingEnabled() {
+		tp, err := initTracer(ctx)
+		if err != nil {
+			log.Fatalf("init tracer: %v", err)
+		}
+		defer func() {
+			// Flush the remaining spans even though ctx is already canceled on signal
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+			defer cancel()
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				log.Printf("shutdown tracer provider: %v", err)
+			}
+		}()
+	}
 
 	client, err := NewWeaviate(ctx)
 	if err != nil {
@@ -101,13 +195,48 @@ func main() {
 
 	server := NewMCP()
 	server.AddTools(client)
+	server.AddPrompts(client)
+
+	if httpAddr != "" {
+		shuttingDown, startShutdown := context.WithCancel(context.Background())
+		defer startShutdown()
+		handler := closeStreamsOn(shuttingDown, mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+			return server.Server
+		}, nil))
+		httpSrv := &http.Server{
+			Addr:    httpAddr,
+			Handler: handler,
+			BaseContext: func(net.Listener) context.Context {
+				// Detach from the signal context so in-flight requests can finish while shutting down
+				return context.WithoutCancel(ctx)
+			},
+		}
+		httpSrv.RegisterOnShutdown(startShutdown)
+		shutdownDone := make(chan struct{})
+		go func() {
+			defer close(shutdownDone)
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+			defer cancel()
+			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+				log.Printf("shutdown http server: %v", err)
+				httpSrv.Close()
+			}
+		}()
+		log.Printf("weaviate MCP server running on http://%s", httpAddr)
+		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("serve http server: %v", err)
+		}
+		<-shutdownDone
+		return
+	}
 
 	tr := &mcp.LoggingTransport{
 		Transport: &mcp.StdioTransport{},
 		Writer:    os.Stderr,
 	}
 
-	if err := server.Run(ctx, tr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	if err := server.Run(ctx, tr); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, context.Canceled) {
 		log.Fatalf("run server: %v", err)
 	}
 }
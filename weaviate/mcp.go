@@ -17,10 +17,18 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	json "encoding/json/v2"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 const instructions = `
@@ -54,39 +62,202 @@ func NewMCP() *mcpServer {
 }
 
 func (s *mcpServer) AddTools(client *weaviateClient) {
+	s.AddReceivingMiddleware(traceToolCalls)
+
 	getSchemaTool := &mcp.Tool{
 		Name:        "get_schema",
 		Description: "Get a weaviate schema",
 	}
 	mcp.AddTool(s.Server, getSchemaTool, client.GetSchema)
 
+	healthCheckTool := &mcp.Tool{
+		Name:        "health_check",
+		Description: "Check the readiness and liveness of the Weaviate cluster and get its version and modules",
+	}
+	mcp.AddTool(s.Server, healthCheckTool, client.HealthCheck)
+
+	listCollectionsTool := &mcp.Tool{
+		Name:        "list_collections",
+		Description: "List the collection (class) names of the weaviate schema",
+	}
+	mcp.AddTool(s.Server, listCollectionsTool, client.ListCollections)
+
+	getCollectionTool := &mcp.Tool{
+		Name:        "get_collection",
+		Description: "Get a single collection (class) definition of the weaviate schema",
+	}
+	mcp.AddTool(s.Server, getCollectionTool, client.GetCollection)
+
 	createSchemaClassTool := &mcp.Tool{
 		Name:        "create_schema_class",
-		Description: "Create a schema class",
+		Description: "Create a schema class from the given class name, properties and vectors, or from a named preset such as \"go\". The source properties of each vector must be declared properties",
 	}
 	mcp.AddTool(s.Server, createSchemaClassTool, client.CreateSchemaClass)
 
+	deleteCollectionTool := &mcp.Tool{
+		Name:        "delete_collection",
+		Description: "Delete a collection (class) and all of its objects. Requires confirm to be true",
+	}
+	mcp.AddTool(s.Server, deleteCollectionTool, client.DeleteCollection)
+
+	createTenantsTool := &mcp.Tool{
+		Name:        "create_tenants",
+		Description: "Create tenants of a multi-tenant collection (class)",
+	}
+	mcp.AddTool(s.Server, createTenantsTool, client.CreateTenants)
+
 	insertOneTool := &mcp.Tool{
 		Name:        "insert_one",
 		Description: "Insert one object to collection",
 	}
 	mcp.AddTool(s.Server, insertOneTool, client.InsertOne)
 
+	insertManyTool := &mcp.Tool{
+		Name:        "insert_many",
+		Description: "Insert many objects to collection in a single batch",
+	}
+	mcp.AddTool(s.Server, insertManyTool, client.InsertMany)
+
+	getObjectTool := &mcp.Tool{
+		Name:        "get_object",
+		Description: "Get a single object in collection by its ID, optionally with its vector",
+	}
+	mcp.AddTool(s.Server, getObjectTool, client.GetObject)
+
+	updateObjectTool := &mcp.Tool{
+		Name:        "update_object",
+		Description: "Update an object in collection, replacing it or merging the given properties",
+	}
+	mcp.AddTool(s.Server, updateObjectTool, client.UpdateObject)
+
 	queryTool := &mcp.Tool{
 		Name:        "query",
-		Description: "Query data within Weaviate using hybrid search",
+		Description: "Query data within Weaviate using hybrid (default), near-text, near-vector or BM25 search. A limit above the server maximum is capped at it",
 	}
 	mcp.AddTool(s.Server, queryTool, client.Query)
+
+	generativeQueryTool := &mcp.Tool{
+		Name:        "generative_query",
+		Description: "Search a collection with hybrid search and generate text from the results with the generative module of the cluster, per result or as a grouped task. A limit above the server maximum is capped at it",
+	}
+	mcp.AddTool(s.Server, generativeQueryTool, client.GenerativeQuery)
+
+	aggregateTool := &mcp.Tool{
+		Name:        "aggregate",
+		Description: "Count the objects in a Weaviate collection, optionally grouped by a property, with minimum, maximum and mean of numeric properties",
+	}
+	mcp.AddTool(s.Server, aggregateTool, client.Aggregate)
+}
+
+// tracer creates the tool call spans. It follows the global tracer provider, so it is a no-op unless tracing is enabled.
+var tracer = otel.Tracer("github.com/zchee/mcp-servers/weaviate-mcp")
+
+// traceToolCalls is a receiving middleware that wraps each tool call in a span named after the tool.
+//
+// The span records the collection and query arguments, if any, and is marked as errored when the tool fails.
+func traceToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		ctx, span := tracer.Start(ctx, method+" "+call.Params.Name)
+		defer span.End()
+
+		span.SetAttributes(attribute.String("mcp.tool.name", call.Params.Name))
+		var args struct {
+			Collection string `json:"collection"`
+			ClassName  string `json:"className"`
+			Query      string `json:"query"`
+		}
+		// Best effort: the tool handler reports malformed arguments itself
+		if err := json.Unmarshal(call.Params.Arguments, &args); err == nil {
+			if collection := cmp.Or(args.Collection, args.ClassName); collection != "" {
+				span.SetAttributes(attribute.String("weaviate.collection", collection))
+			}
+			if args.Query != "" {
+				span.SetAttributes(attribute.String("weaviate.query", args.Query))
+			}
+		}
+
+		res, err := next(ctx, method, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result, ok := res.(*mcp.CallToolResult); ok && result != nil && result.IsError {
+			span.SetStatus(codes.Error, toolErrorText(result))
+		}
+
+		return res, err
+	}
+}
+
+// toolErrorText returns the text of the error result res.
+func toolErrorText(res *mcp.CallToolResult) string {
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+
+	return "tool call failed"
 }
 
 func (s *mcpServer) AddPrompts(client *weaviateClient) {
-	prompt := &mcp.Prompt{
-		Name:        "get_schema",
-		Description: "Get a weaviate schema",
-		Arguments:   []*mcp.PromptArgument{},
+	codeSnippetExtractionPrompt := &mcp.Prompt{
+		Name:        "code_snippet_extraction",
+		Description: "Extract a code snippet with its explanation, technical details and key features, and store it in a Weaviate collection",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "language",
+				Description: "programming language of the code snippet, e.g. Go",
+				Required:    true,
+			},
+			{
+				Name:        "code",
+				Description: "code snippet to extract; if omitted, the snippet is taken from the conversation",
+			},
+			{
+				Name:        "collection",
+				Description: "collection to store the extracted snippet in (default \"Go\")",
+			},
+		},
+	}
+	s.AddPrompt(codeSnippetExtractionPrompt, codeSnippetExtraction)
+}
+
+// codeSnippetExtraction renders the code_snippet_extraction prompt.
+func codeSnippetExtraction(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	language := args["language"]
+	if language == "" {
+		return nil, errors.New("language argument is required")
 	}
-	promptHandler := func(ctx context.Context, gpr *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		return nil, nil
+	collection := cmp.Or(args["collection"], "Go")
+
+	var sb strings.Builder
+	code := args["code"]
+	if code == "" {
+		fmt.Fprintf(&sb, "Analyze the %s code snippet in this conversation.\n\n", language)
+	} else {
+		fmt.Fprintf(&sb, "Analyze the following %s code snippet.\n\n", language)
 	}
-	s.AddPrompt(prompt, promptHandler)
+	sb.WriteString(strings.TrimSpace(instructions))
+	fmt.Fprintf(&sb, "\n\nThen store the result in the %q collection with the insert_one tool.", collection)
+	if code != "" {
+		fmt.Fprintf(&sb, "\n\n```%s\n%s\n```", strings.ToLower(language), code)
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Extract a %s code snippet into the %q collection", language, collection),
+		Messages: []*mcp.PromptMessage{
+			{
+				Role: "user",
+				Content: &mcp.TextContent{
+					Text: sb.String(),
+				},
+			},
+		},
+	}, nil
 }
@@ -17,16 +17,28 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	json "encoding/json/v2"
 	"errors"
 	"fmt"
+	"log"
+	"maps"
+	"math"
 	"net/http"
 	"net/http/httptrace"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/weaviate/weaviate-go-client/v5/weaviate"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/auth"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/connection"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/fault"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/filters"
 	weaviate_graphql "github.com/weaviate/weaviate-go-client/v5/weaviate/graphql"
 	weaviate_grpc "github.com/weaviate/weaviate-go-client/v5/weaviate/grpc"
 	"github.com/weaviate/weaviate/entities/models"
@@ -36,8 +48,22 @@ import (
 	"golang.org/x/oauth2"
 )
 
+const (
+	// defaultQueryLimit is the number of results the query tool returns when no limit is given.
+	defaultQueryLimit = 10
+
+	// defaultMaxQueryLimit is the default upper bound of the query tool limit.
+	defaultMaxQueryLimit = 100
+
+	// insertBatchSize is the number of objects insert_many sends per batch request.
+	insertBatchSize = 100
+)
+
 type weaviateClient struct {
 	*weaviate.Client
+
+	// maxQueryLimit is the upper bound of the query tool limit.
+	maxQueryLimit int
 }
 
 // NewWeaviate creates a new weaviate client.
@@ -50,20 +76,34 @@ func NewWeaviate(ctx context.Context) (*weaviateClient, error) {
 			}),
 		),
 	}
-	_ = oauth2.NewClient
+	scheme := cmp.Or(os.Getenv(envWeaviateScheme), "https")
+	if scheme != "https" && scheme != "http" {
+		return nil, fmt.Errorf("invalid %s %q: must be http or https", envWeaviateScheme, scheme)
+	}
+
+	// gRPC is disabled unless a gRPC host is given
+	var grpcConfig *weaviate_grpc.Config
+	if host := os.Getenv(envWeaviateGRPCURL); host != "" {
+		secured := true
+		if v := os.Getenv(envWeaviateGRPCSecured); v != "" {
+			var err error
+			secured, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: must be a boolean", envWeaviateGRPCSecured, v)
+			}
+		}
+		grpcConfig = &weaviate_grpc.Config{
+			Host:    host,
+			Secured: secured,
+		}
+	}
+
 	cfg := weaviate.Config{
 		Host:             os.Getenv(envWeaviateURL),
-		Scheme:           "https",
+		Scheme:           scheme,
 		ConnectionClient: cc,
-		GrpcConfig: &weaviate_grpc.Config{
-			Host:    os.Getenv(envWeaviateGRPCURL),
-			Secured: true,
-		},
-		// AuthConfig: auth.ApiKey{
-		// 	Value: os.Getenv(envWeaviateAPIKey),
-		// },
+		GrpcConfig:       grpcConfig,
 		Headers: map[string]string{
-			"Authorization":         "Bearer " + os.Getenv(envWeaviateAPIKey),
 			"X-HuggingFace-Api-Key": os.Getenv(envHuggingFaceAPIKey),
 			"X-VoyageAI-Api-Key":    os.Getenv(envVoyageAIAPIKey),
 			"X-Cohere-Api-Key":      os.Getenv(envCohereAPIKey),
@@ -71,6 +111,30 @@ func NewWeaviate(ctx context.Context) (*weaviateClient, error) {
 		},
 	}
 
+	var authConfig auth.Config
+	switch {
+	case os.Getenv(envWeaviateAPIKey) != "":
+		authConfig = auth.ApiKey{
+			Value: os.Getenv(envWeaviateAPIKey),
+		}
+	case os.Getenv(envWeaviateClientSecret) != "":
+		var scopes []string
+		for scope := range strings.SplitSeq(os.Getenv(envWeaviateOIDCScopes), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		authConfig = auth.ClientCredentials{
+			ClientSecret: os.Getenv(envWeaviateClientSecret),
+			Scopes:       scopes,
+		}
+	}
+	if authConfig != nil {
+		if err := authenticate(&cfg, authConfig); err != nil {
+			return nil, fmt.Errorf("authenticate to weaviate: %w", err)
+		}
+	}
+
 	client, err := weaviate.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create to weaviate client: %w", err)
@@ -81,11 +145,45 @@ func NewWeaviate(ctx context.Context) (*weaviateClient, error) {
 		return nil, fmt.Errorf("check the weaviate connection: %w", err)
 	}
 
+	maxQueryLimit := defaultMaxQueryLimit
+	if v := os.Getenv(envMaxQueryLimit); v != "" {
+		maxQueryLimit, err = strconv.Atoi(v)
+		if err != nil || maxQueryLimit <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a number > 0", envMaxQueryLimit, v)
+		}
+	}
+
 	return &weaviateClient{
-		Client: client,
+		Client:        client,
+		maxQueryLimit: maxQueryLimit,
 	}, nil
 }
 
+// authenticate resolves authConfig into the headers and token refreshing transport of cfg.
+//
+// weaviate.Config.AuthConfig can't be combined with a ConnectionClient, so this does what [weaviate.NewClient] does
+// with it while keeping the instrumented transport of cfg.ConnectionClient under the OIDC token transport.
+func authenticate(cfg *weaviate.Config, authConfig auth.Config) error {
+	// The OIDC flows discover the token endpoint through this connection
+	con := connection.NewConnection(cfg.Scheme, cfg.Host, nil, 0, cfg.Headers)
+	client, headers, err := authConfig.GetAuthInfo(con)
+	if err != nil {
+		return err
+	}
+	maps.Copy(cfg.Headers, headers)
+
+	if client != nil {
+		transport, ok := client.Transport.(*oauth2.Transport)
+		if !ok {
+			return fmt.Errorf("unexpected OIDC transport %T", client.Transport)
+		}
+		transport.Base = cfg.ConnectionClient.Transport
+		cfg.ConnectionClient.Transport = transport
+	}
+
+	return nil
+}
+
 // GetSchema get a weaviate schema.
 func (w *weaviateClient) GetSchema(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
 	scm, err := w.Schema().Getter().Do(ctx)
@@ -106,11 +204,119 @@ func (w *weaviateClient) GetSchema(ctx context.Context, _ *mcp.CallToolRequest,
 	}, nil, nil
 }
 
-// CreateSchemaClass creates a schema class.
-func (w *weaviateClient) CreateSchemaClass(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-	className := "Go"
-	hfClass := &models.Class{
-		Class: className,
+// ListCollections lists the class names of the weaviate schema.
+func (w *weaviateClient) ListCollections(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	scm, err := w.Schema().Getter().Do(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get schema: %w", err)
+	}
+	names := make([]string, 0, len(scm.Classes))
+	for _, class := range scm.Classes {
+		names = append(names, class.Class)
+	}
+	slices.Sort(names)
+
+	data, err := json.Marshal(names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal collection names: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil, nil
+}
+
+type getCollectionArgs struct {
+	ClassName string `json:"className" jsonschema:"name of the class to get"`
+}
+
+// GetCollection gets a single class definition of the weaviate schema.
+func (w *weaviateClient) GetCollection(ctx context.Context, _ *mcp.CallToolRequest, args getCollectionArgs) (*mcp.CallToolResult, any, error) {
+	class, err := w.Schema().ClassGetter().WithClassName(args.ClassName).Do(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get class %q: %w", args.ClassName, responseErrors(err))
+	}
+	data, err := json.Marshal(class)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal class: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil, nil
+}
+
+// healthStatus is the result of the health_check tool.
+type healthStatus struct {
+	Ready    bool   `json:"ready"`
+	Live     bool   `json:"live"`
+	Hostname string `json:"hostname,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Modules  any    `json:"modules,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HealthCheck checks the readiness and liveness of the weaviate cluster and returns its meta information.
+//
+// The result is an error result when the cluster is not ready.
+func (w *weaviateClient) HealthCheck(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	var (
+		status healthStatus
+		errs   []error
+		err    error
+	)
+	if status.Ready, err = w.Misc().ReadyChecker().Do(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("check readiness: %w", err))
+	}
+	if status.Live, err = w.Misc().LiveChecker().Do(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("check liveness: %w", err))
+	}
+	if status.Ready {
+		meta, err := w.Misc().MetaGetter().Do(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get meta: %w", err))
+		} else {
+			status.Hostname = meta.Hostname
+			status.Version = meta.Version
+			status.Modules = meta.Modules
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		status.Error = err.Error()
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal health status: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+		IsError: !status.Ready,
+	}, nil, nil
+}
+
+// classPresets are the named class definitions create_schema_class can create without spelling out every property.
+var classPresets = map[string]func() *models.Class{
+	"go": goClass,
+}
+
+// goClass returns the "Go" class definition for indexing Go code snippets with the HuggingFace vectorizer.
+func goClass() *models.Class {
+	return &models.Class{
+		Class: "Go",
 		Properties: []*models.Property{
 			{
 				Name:     "title",
@@ -232,63 +438,598 @@ func (w *weaviateClient) CreateSchemaClass(ctx context.Context, _ *mcp.CallToolR
 			},
 		},
 	}
+}
+
+// propertySpec describes a single property of a class created by create_schema_class.
+type propertySpec struct {
+	Name     string `json:"name" jsonschema:"property name"`
+	DataType string `json:"dataType" jsonschema:"property data type, e.g. text, text[], int, number, boolean, date, uuid"`
+}
+
+// vectorSpec describes a named vector of a class created by create_schema_class.
+type vectorSpec struct {
+	Name             string   `json:"name" jsonschema:"vector name"`
+	Vectorizer       string   `json:"vectorizer" jsonschema:"vectorizer module, e.g. text2vec-huggingface"`
+	Model            string   `json:"model,omitempty" jsonschema:"model passed to the vectorizer module"`
+	SourceProperties []string `json:"sourceProperties,omitempty" jsonschema:"declared properties the vector is computed from. The vectorizer module picks them when empty"`
+}
+
+type createSchemaClassArgs struct {
+	Preset     string         `json:"preset,omitempty" jsonschema:"name of a predefined class to create (go). Used when className is empty"`
+	ClassName  string         `json:"className,omitempty" jsonschema:"class name"`
+	Properties []propertySpec `json:"properties,omitempty" jsonschema:"class properties"`
+	Vectorizer string         `json:"vectorizer,omitempty" jsonschema:"vectorizer module, e.g. text2vec-huggingface. Weaviate's default is used when empty"`
+	Model      string         `json:"model,omitempty" jsonschema:"model passed to the vectorizer module"`
+	Vectors    []vectorSpec   `json:"vectors,omitempty" jsonschema:"named vectors, each with its own vectorizer and source properties. Can't be used with vectorizer"`
+	DryRun     bool           `json:"dryRun,omitempty" jsonschema:"if true, only validate the class and return its definition without creating it"`
+}
+
+// buildClass builds the class definition described by args.
+func buildClass(args createSchemaClassArgs) (*models.Class, error) {
+	if args.ClassName == "" {
+		preset := cmp.Or(args.Preset, "go")
+		newClass, ok := classPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q: must be one of %s", preset, strings.Join(slices.Sorted(maps.Keys(classPresets)), ", "))
+		}
+		return newClass(), nil
+	}
+
+	if len(args.Properties) == 0 {
+		return nil, errors.New("properties must not be empty")
+	}
+	class := &models.Class{
+		Class:      args.ClassName,
+		Properties: make([]*models.Property, 0, len(args.Properties)),
+	}
+	for _, prop := range args.Properties {
+		if prop.Name == "" {
+			return nil, errors.New("property name must not be empty")
+		}
+		if !slices.Contains(schema.PrimitiveDataTypes, schema.DataType(prop.DataType)) {
+			return nil, fmt.Errorf("property %q: unknown data type %q", prop.Name, prop.DataType)
+		}
+		class.Properties = append(class.Properties, &models.Property{
+			Name:     prop.Name,
+			DataType: schema.DataType(prop.DataType).PropString(),
+		})
+	}
+
+	switch {
+	case args.Vectorizer != "" && len(args.Vectors) > 0:
+		return nil, errors.New("vectorizer and vectors can't be used together: name the vector in vectors instead")
+	case args.Vectorizer != "":
+		class.VectorConfig = map[string]models.VectorConfig{
+			"default": vectorConfig(args.Vectorizer, args.Model, nil),
+		}
+	case len(args.Vectors) > 0:
+		class.VectorConfig = make(map[string]models.VectorConfig, len(args.Vectors))
+		for i, vector := range args.Vectors {
+			if vector.Name == "" || vector.Vectorizer == "" {
+				return nil, fmt.Errorf("vector %d: name and vectorizer are required", i)
+			}
+			if _, ok := class.VectorConfig[vector.Name]; ok {
+				return nil, fmt.Errorf("vector %q: duplicate name", vector.Name)
+			}
+			class.VectorConfig[vector.Name] = vectorConfig(vector.Vectorizer, vector.Model, vector.SourceProperties)
+		}
+	}
+
+	return class, nil
+}
+
+// vectorConfig returns the configuration of an HNSW indexed vector computed by the vectorizer module.
+//
+// The model and sourceProperties module settings are only set when not empty.
+func vectorConfig(vectorizer, model string, sourceProperties []string) models.VectorConfig {
+	moduleConfig := map[string]any{}
+	if model != "" {
+		moduleConfig["model"] = model
+	}
+	if len(sourceProperties) > 0 {
+		moduleConfig["sourceProperties"] = sourceProperties
+	}
+
+	return models.VectorConfig{
+		VectorIndexType: "hnsw",
+		Vectorizer: map[string]any{
+			vectorizer: moduleConfig,
+		},
+	}
+}
+
+// validateClass checks that the source properties of each vectorizer of class are declared properties of class.
+func validateClass(class *models.Class) error {
+	declared := make(map[string]bool, len(class.Properties))
+	for _, prop := range class.Properties {
+		declared[prop.Name] = true
+	}
+
+	var errs []error
+	for _, vector := range slices.Sorted(maps.Keys(class.VectorConfig)) {
+		vectorizers, _ := class.VectorConfig[vector].Vectorizer.(map[string]any)
+		for module, config := range vectorizers {
+			moduleConfig, _ := config.(map[string]any)
+			for _, name := range sourceProperties(moduleConfig["sourceProperties"]) {
+				if !declared[name] {
+					errs = append(errs, fmt.Errorf("vector %q: %s source property %q is not a property of class %q", vector, module, name, class.Class))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sourceProperties returns the property names of the sourceProperties vectorizer setting v.
+func sourceProperties(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		names := make([]string, 0, len(v))
+		for _, name := range v {
+			if name, ok := name.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
 
-	if err := w.Schema().ClassCreator().WithClass(hfClass).Do(ctx); err != nil {
+// CreateSchemaClass creates a schema class.
+//
+// If args.DryRun is true, the class is only validated and its definition is returned instead.
+func (w *weaviateClient) CreateSchemaClass(ctx context.Context, _ *mcp.CallToolRequest, args createSchemaClassArgs) (*mcp.CallToolResult, any, error) {
+	class, err := buildClass(args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build schema class: %w", err)
+	}
+	if err := validateClass(class); err != nil {
+		return nil, nil, fmt.Errorf("validate schema class: %w", err)
+	}
+
+	if args.DryRun {
+		b, err := json.Marshal(class)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal schema class: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(b),
+				},
+			},
+		}, nil, nil
+	}
+
+	if err := w.Schema().ClassCreator().WithClass(class).Do(ctx); err != nil {
 		return nil, nil, fmt.Errorf("create schema class: %w", err)
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("created %q schema class", className),
+				Text: fmt.Sprintf("created %q schema class", class.Class),
+			},
+		},
+	}, nil, nil
+}
+
+type deleteCollectionArgs struct {
+	ClassName string `json:"className" jsonschema:"name of the class to delete"`
+	Confirm   bool   `json:"confirm" jsonschema:"must be true to delete the class and all of its objects"`
+}
+
+// DeleteCollection deletes a schema class and all of its objects.
+func (w *weaviateClient) DeleteCollection(ctx context.Context, _ *mcp.CallToolRequest, args deleteCollectionArgs) (*mcp.CallToolResult, any, error) {
+	if !args.Confirm {
+		return nil, nil, fmt.Errorf("refusing to delete class %q and all of its objects: set confirm to true to proceed", args.ClassName)
+	}
+
+	exists, err := w.Schema().ClassExistenceChecker().WithClassName(args.ClassName).Do(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("check class %q: %w", args.ClassName, responseErrors(err))
+	}
+	text := fmt.Sprintf("class %q not found, nothing to delete", args.ClassName)
+	if exists {
+		if err := w.Schema().ClassDeleter().WithClassName(args.ClassName).Do(ctx); err != nil {
+			return nil, nil, fmt.Errorf("delete class %q: %w", args.ClassName, responseErrors(err))
+		}
+		text = fmt.Sprintf("deleted class %q", args.ClassName)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
 			},
 		},
 	}, nil, nil
 }
 
+type createTenantsArgs struct {
+	ClassName string   `json:"className" jsonschema:"name of the multi-tenant class"`
+	Tenants   []string `json:"tenants" jsonschema:"names of the tenants to create"`
+}
+
+// CreateTenants creates tenants of a multi-tenant class.
+func (w *weaviateClient) CreateTenants(ctx context.Context, _ *mcp.CallToolRequest, args createTenantsArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.Tenants) == 0 {
+		return nil, nil, errors.New("tenants must not be empty")
+	}
+
+	tenants := make([]models.Tenant, len(args.Tenants))
+	for i, name := range args.Tenants {
+		if name == "" {
+			return nil, nil, fmt.Errorf("tenant %d: name must not be empty", i)
+		}
+		tenants[i] = models.Tenant{Name: name}
+	}
+	if err := w.Schema().TenantsCreator().WithClassName(args.ClassName).WithTenants(tenants...).Do(ctx); err != nil {
+		return nil, nil, fmt.Errorf("create tenants of class %q: %w", args.ClassName, responseErrors(err))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("created %d tenants of class %q: %s", len(args.Tenants), args.ClassName, strings.Join(args.Tenants, ", ")),
+			},
+		},
+	}, nil, nil
+}
+
+// referenceArgs describes a cross-reference from the inserted object to another object.
+type referenceArgs struct {
+	Property         string `json:"property" jsonschema:"reference property of the inserted object"`
+	TargetCollection string `json:"targetCollection" jsonschema:"collection of the referenced object"`
+	TargetID         string `json:"targetId" jsonschema:"ID of the referenced object"`
+}
+
 type insertOneArgs struct {
-	Collection string `json:"collection" jsonschema:"collection name"`
-	Properties any    `json:"properties" jsonschema:"insert properties"`
+	Collection string          `json:"collection" jsonschema:"collection name"`
+	Properties any             `json:"properties" jsonschema:"insert properties"`
+	References []referenceArgs `json:"references,omitempty" jsonschema:"cross-references to add from the inserted object to other objects"`
+	Tenant     string          `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
 }
 
 func (w *weaviateClient) InsertOne(ctx context.Context, _ *mcp.CallToolRequest, args insertOneArgs) (*mcp.CallToolResult, any, error) {
+	for i, ref := range args.References {
+		if ref.Property == "" || ref.TargetCollection == "" || ref.TargetID == "" {
+			return nil, nil, fmt.Errorf("reference %d: property, targetCollection and targetId are required", i)
+		}
+	}
+
 	obj := models.Object{
 		Class:      args.Collection,
 		Properties: args.Properties,
+		Tenant:     args.Tenant,
 	}
 
 	// Use batch to leverage autoschema and gRPC
-	_, err := w.batchInsert(ctx, &obj)
+	resp, err := w.batchInsert(ctx, &obj)
 	if err != nil {
 		return nil, nil, fmt.Errorf("insert one object: %w", err)
 	}
+	if len(args.References) == 0 {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	if len(resp) == 0 || resp[0].ID == "" {
+		return nil, nil, errors.New("insert one object: no object ID in the response to add references from")
+	}
+
+	id := resp[0].ID.String()
+	if err := w.createReferences(ctx, args.Collection, id, args.Tenant, args.References); err != nil {
+		return nil, nil, fmt.Errorf("object %s was inserted, but adding its references failed: %w", id, err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("inserted object %s with %d references", id, len(args.References)),
+			},
+		},
+	}, nil, nil
+}
+
+// createReferences adds the refs cross-references from the object id in collection.
+//
+// If tenant is not empty, the object belongs to that tenant of the multi-tenant collection.
+func (w *weaviateClient) createReferences(ctx context.Context, collection, id, tenant string, refs []referenceArgs) error {
+	var err error
+	for _, ref := range refs {
+		payload := w.Data().ReferencePayloadBuilder().
+			WithClassName(ref.TargetCollection).
+			WithID(ref.TargetID).
+			Payload()
+		refErr := w.Data().ReferenceCreator().
+			WithClassName(collection).
+			WithID(id).
+			WithReferenceProperty(ref.Property).
+			WithReference(payload).
+			WithTenant(tenant).
+			Do(ctx)
+		if refErr != nil {
+			err = errors.Join(err, fmt.Errorf("reference %s to %s/%s: %w", ref.Property, ref.TargetCollection, ref.TargetID, responseErrors(refErr)))
+		}
+	}
+
+	return err
+}
+
+type insertManyArgs struct {
+	Collection string `json:"collection" jsonschema:"collection name"`
+	Objects    []any  `json:"objects" jsonschema:"properties of each object to insert"`
+	Tenant     string `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
+}
+
+// InsertMany inserts many objects to collection in batches of insertBatchSize objects.
+//
+// If the client asked for progress, a progress notification is sent after each batch.
+func (w *weaviateClient) InsertMany(ctx context.Context, req *mcp.CallToolRequest, args insertManyArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.Objects) == 0 {
+		return nil, nil, errors.New("objects must not be empty")
+	}
+
+	objs := make([]*models.Object, len(args.Objects))
+	for i, props := range args.Objects {
+		objs[i] = &models.Object{
+			Class:      args.Collection,
+			Properties: props,
+			Tenant:     args.Tenant,
+		}
+	}
+
+	var progressToken any
+	if req != nil && req.Params != nil {
+		progressToken = req.Params.GetProgressToken()
+	}
+
+	var sb strings.Builder
+	failed := 0
+	for start := 0; start < len(objs); start += insertBatchSize {
+		batch := objs[start:min(start+insertBatchSize, len(objs))]
+		resp, err := w.batchInsert(ctx, batch...)
+		if resp == nil && err != nil {
+			// The whole batch request failed, so none of its objects were inserted
+			failed += len(batch)
+			fmt.Fprintf(&sb, "\nobjects %d-%d: %v", start, start+len(batch)-1, err)
+		}
+		for i, res := range resp {
+			if msgs := objectErrors(res); len(msgs) > 0 {
+				failed++
+				fmt.Fprintf(&sb, "\nobject %d: %s", start+i, strings.Join(msgs, "; "))
+			}
+		}
+
+		if progressToken != nil {
+			done := start + len(batch)
+			params := &mcp.ProgressNotificationParams{
+				ProgressToken: progressToken,
+				Progress:      float64(done),
+				Total:         float64(len(objs)),
+				Message:       fmt.Sprintf("inserted %d of %d objects", done-failed, len(objs)),
+			}
+			if err := req.Session.NotifyProgress(ctx, params); err != nil {
+				log.Printf("notify insert_many progress: %v", err)
+			}
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("inserted %d of %d objects into %q", len(objs)-failed, len(objs), args.Collection) + sb.String(),
+			},
+		},
+		IsError: failed == len(objs),
+	}, nil, nil
+}
 
-	return &mcp.CallToolResult{}, nil, nil
+// whereFilter describes a filter on a single property of the queried collection.
+type whereFilter struct {
+	Path      []string `json:"path" jsonschema:"property path to filter on, e.g. [\"go_version\"]"`
+	Operator  string   `json:"operator" jsonschema:"filter operator: Equal, NotEqual, GreaterThan, GreaterThanEqual, LessThan, LessThanEqual or Like"`
+	Value     any      `json:"value" jsonschema:"value to compare with"`
+	ValueType string   `json:"valueType,omitempty" jsonschema:"data type of the filtered property: text, int, number, boolean or date (an RFC 3339 string). Required for numbers; defaults to text for strings and boolean for booleans"`
 }
 
+// whereOperators are the operators supported by [whereFilter].
+var whereOperators = []filters.WhereOperator{
+	filters.Equal,
+	filters.NotEqual,
+	filters.GreaterThan,
+	filters.GreaterThanEqual,
+	filters.LessThan,
+	filters.LessThanEqual,
+	filters.Like,
+}
+
+// build translates f into a where filter builder.
+func (f *whereFilter) build() (*filters.WhereBuilder, error) {
+	if len(f.Path) == 0 {
+		return nil, errors.New("where: path must not be empty")
+	}
+	operator := filters.WhereOperator(f.Operator)
+	if !slices.Contains(whereOperators, operator) {
+		ops := make([]string, len(whereOperators))
+		for i, op := range whereOperators {
+			ops[i] = string(op)
+		}
+		return nil, fmt.Errorf("where: unknown operator %q: must be one of %s", f.Operator, strings.Join(ops, ", "))
+	}
+
+	valueType := schema.DataType(f.ValueType)
+	if valueType == "" {
+		switch f.Value.(type) {
+		case string:
+			valueType = schema.DataTypeText
+		case bool:
+			valueType = schema.DataTypeBoolean
+		default:
+			// An integral JSON number may be compared with an int or a number property, so don't guess
+			return nil, fmt.Errorf("where: valueType is required for a %T value: must be int or number", f.Value)
+		}
+	}
+
+	where := filters.Where().WithPath(f.Path).WithOperator(operator)
+	switch valueType {
+	case schema.DataTypeText:
+		v, ok := f.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("where: %s value must be a string, got %T", valueType, f.Value)
+		}
+		where.WithValueText(v)
+
+	case schema.DataTypeBoolean:
+		v, ok := f.Value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("where: %s value must be a boolean, got %T", valueType, f.Value)
+		}
+		where.WithValueBoolean(v)
+
+	case schema.DataTypeInt:
+		v, ok := f.Value.(float64)
+		if !ok || v != math.Trunc(v) {
+			return nil, fmt.Errorf("where: %s value must be an integral number, got %v", valueType, f.Value)
+		}
+		where.WithValueInt(int64(v))
+
+	case schema.DataTypeNumber:
+		v, ok := f.Value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("where: %s value must be a number, got %T", valueType, f.Value)
+		}
+		where.WithValueNumber(v)
+
+	case schema.DataTypeDate:
+		v, ok := f.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("where: %s value must be an RFC 3339 string, got %T", valueType, f.Value)
+		}
+		date, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("where: invalid %s value: %w", valueType, err)
+		}
+		where.WithValueDate(date)
+
+	default:
+		return nil, fmt.Errorf("where: unknown valueType %q: must be text, int, number, boolean or date", f.ValueType)
+	}
+
+	return where, nil
+}
+
+// Search modes supported by the query tool.
+const (
+	searchModeHybrid     = "hybrid"
+	searchModeNearText   = "nearText"
+	searchModeNearVector = "nearVector"
+	searchModeBM25       = "bm25"
+)
+
 type queryArgs struct {
-	Collection       string   `json:"collection" jsonschema:"collection name"`
-	Query            string   `json:"query" jsonschema:"search query"`
-	TargetProperties []string `json:"targetProperties" jsonschema:"target properties"`
+	Collection       string       `json:"collection" jsonschema:"collection name"`
+	Query            string       `json:"query,omitempty" jsonschema:"search query, required for all modes except nearVector"`
+	SearchMode       string       `json:"searchMode,omitempty" jsonschema:"search mode: hybrid (default), nearText, nearVector or bm25"`
+	Vector           []float32    `json:"vector,omitempty" jsonschema:"search vector, required for the nearVector mode"`
+	Properties       []string     `json:"properties,omitempty" jsonschema:"properties to search in the bm25 mode (default all text properties)"`
+	TargetProperties []string     `json:"targetProperties" jsonschema:"target properties"`
+	Where            *whereFilter `json:"where,omitempty" jsonschema:"optional filter applied to the search results"`
+	Limit            int          `json:"limit,omitempty" jsonschema:"maximum number of results (default 10), capped at WEAVIATE_MAX_QUERY_LIMIT"`
+	Offset           int          `json:"offset,omitempty" jsonschema:"number of results to skip, for pagination"`
+	Tenant           string       `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
+}
+
+// withSearch applies the search mode of args to get.
+func (args *queryArgs) withSearch(get *weaviate_graphql.GetBuilder) (*weaviate_graphql.GetBuilder, error) {
+	mode := cmp.Or(args.SearchMode, searchModeHybrid)
+	if mode != searchModeNearVector && args.Query == "" {
+		return nil, fmt.Errorf("query is required for the %s search mode", mode)
+	}
+
+	switch mode {
+	case searchModeHybrid:
+		hybrid := weaviate_graphql.HybridArgumentBuilder{}
+		hybrid.WithQuery(args.Query)
+		return get.WithHybrid(&hybrid), nil
+
+	case searchModeNearText:
+		nearText := weaviate_graphql.NearTextArgumentBuilder{}
+		nearText.WithConcepts([]string{args.Query})
+		return get.WithNearText(&nearText), nil
+
+	case searchModeNearVector:
+		if len(args.Vector) == 0 {
+			return nil, errors.New("vector is required for the nearVector search mode")
+		}
+		nearVector := weaviate_graphql.NearVectorArgumentBuilder{}
+		nearVector.WithVector(args.Vector)
+		return get.WithNearVector(&nearVector), nil
+
+	case searchModeBM25:
+		bm25 := weaviate_graphql.BM25ArgumentBuilder{}
+		bm25.WithQuery(args.Query)
+		if len(args.Properties) > 0 {
+			bm25.WithProperties(args.Properties...)
+		}
+		return get.WithBM25(&bm25), nil
+
+	default:
+		return nil, fmt.Errorf("unknown search mode %q: must be one of %s, %s, %s or %s",
+			args.SearchMode, searchModeHybrid, searchModeNearText, searchModeNearVector, searchModeBM25)
+	}
 }
 
 func (w *weaviateClient) Query(ctx context.Context, req *mcp.CallToolRequest, args queryArgs) (*mcp.CallToolResult, any, error) {
-	hybrid := weaviate_graphql.HybridArgumentBuilder{}
-	hybrid.WithQuery(args.Query)
+	if args.Limit < 0 || args.Offset < 0 {
+		return nil, nil, errors.New("limit and offset must be numbers >= 0")
+	}
+	limit := min(cmp.Or(args.Limit, defaultQueryLimit), w.maxQueryLimit)
 
-	res, err := w.GraphQL().Get().
-		WithClassName(args.Collection).WithHybrid(&hybrid).
+	get := w.GraphQL().Get().
+		WithClassName(args.Collection).
 		WithFields(func() []weaviate_graphql.Field {
-			fields := make([]weaviate_graphql.Field, len(args.TargetProperties))
+			fields := make([]weaviate_graphql.Field, len(args.TargetProperties), len(args.TargetProperties)+1)
 			for i, prop := range args.TargetProperties {
 				fields[i] = weaviate_graphql.Field{Name: prop}
 			}
-			return fields
+			return append(fields, weaviate_graphql.Field{
+				Name: "_additional",
+				Fields: []weaviate_graphql.Field{
+					{Name: "id"},
+					{Name: "distance"},
+					{Name: "score"},
+				},
+			})
 		}()...).
-		Do(ctx)
+		WithLimit(limit).
+		WithOffset(args.Offset)
+	get, err := args.withSearch(get)
+	if err != nil {
+		return nil, nil, err
+	}
+	if args.Tenant != "" {
+		get = get.WithTenant(args.Tenant)
+	}
+	if args.Where != nil {
+		where, err := args.Where.build()
+		if err != nil {
+			return nil, nil, err
+		}
+		get = get.WithWhere(where)
+	}
+
+	res, err := get.Do(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
+	// GraphQL errors, such as an unknown field, come back in a successful response
+	if err := graphQLErrors(res); err != nil {
+		return nil, nil, fmt.Errorf("query: %w", err)
+	}
 	b, err := json.Marshal(res)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unmarshal query response: %w", err)
@@ -303,6 +1044,348 @@ func (w *weaviateClient) Query(ctx context.Context, req *mcp.CallToolRequest, ar
 	}, nil, nil
 }
 
+type generativeQueryArgs struct {
+	Collection       string   `json:"collection" jsonschema:"collection name"`
+	Query            string   `json:"query" jsonschema:"hybrid search query"`
+	Prompt           string   `json:"prompt" jsonschema:"prompt generated for each result, with {property} placeholders, or the task generated once over all results if grouped is true"`
+	Grouped          bool     `json:"grouped,omitempty" jsonschema:"if true, run prompt once as a grouped task over all results instead of once per result"`
+	TargetProperties []string `json:"targetProperties,omitempty" jsonschema:"properties to return and, for a grouped task, to pass to the generative module"`
+	Limit            int      `json:"limit,omitempty" jsonschema:"maximum number of results (default 10), capped at WEAVIATE_MAX_QUERY_LIMIT"`
+	Tenant           string   `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
+}
+
+// generativeQueryResult is the result of [weaviateClient.GenerativeQuery].
+type generativeQueryResult struct {
+	Generated []string `json:"generated"`
+	Objects   []any    `json:"objects"`
+	// Errors are the generation errors of the objects whose text failed to generate, when others succeeded.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// GenerativeQuery performs a hybrid search and generates text from the results with the generative module of the cluster.
+func (w *weaviateClient) GenerativeQuery(ctx context.Context, _ *mcp.CallToolRequest, args generativeQueryArgs) (*mcp.CallToolResult, any, error) {
+	if args.Query == "" || args.Prompt == "" {
+		return nil, nil, errors.New("query and prompt are required")
+	}
+	if args.Limit < 0 {
+		return nil, nil, errors.New("limit must be a number >= 0")
+	}
+	limit := min(cmp.Or(args.Limit, defaultQueryLimit), w.maxQueryLimit)
+	if err := w.checkGenerativeModule(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	generate := weaviate_graphql.NewGenerativeSearch()
+	if args.Grouped {
+		generate.GroupedResult(args.Prompt, args.TargetProperties...)
+	} else {
+		generate.SingleResult(args.Prompt)
+	}
+	fields := make([]weaviate_graphql.Field, len(args.TargetProperties), len(args.TargetProperties)+1)
+	for i, prop := range args.TargetProperties {
+		fields[i] = weaviate_graphql.Field{Name: prop}
+	}
+	fields = append(fields, weaviate_graphql.Field{
+		Name:   "_additional",
+		Fields: []weaviate_graphql.Field{{Name: "id"}},
+	})
+	hybrid := weaviate_graphql.HybridArgumentBuilder{}
+	hybrid.WithQuery(args.Query)
+
+	get := w.GraphQL().Get().
+		WithClassName(args.Collection).
+		WithFields(fields...).
+		WithHybrid(&hybrid).
+		WithGenerativeSearch(generate).
+		WithLimit(limit)
+	if args.Tenant != "" {
+		get = get.WithTenant(args.Tenant)
+	}
+
+	res, err := get.Do(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := graphQLErrors(res); err != nil {
+		return nil, nil, fmt.Errorf("generative query: %w", err)
+	}
+
+	result, err := generatedResult(res, args.Collection, args.Grouped)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal generative query result: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(b),
+			},
+		},
+	}, nil, nil
+}
+
+// checkGenerativeModule returns an error if the cluster has no generative module enabled.
+func (w *weaviateClient) checkGenerativeModule(ctx context.Context) error {
+	meta, err := w.Misc().MetaGetter().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("get meta: %w", err)
+	}
+	if modules, ok := meta.Modules.(map[string]any); ok {
+		for name := range modules {
+			if strings.HasPrefix(name, "generative-") {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("no generative module is enabled on the Weaviate cluster: enable one such as generative-openai and configure it on the collection")
+}
+
+// generatedResult extracts the objects of collection and their generated text from the generative search response res.
+//
+// A grouped task is generated once, so its text is only on the first object.
+// The generation errors of some objects are returned in the result, and as an error only when no text was generated at all.
+func generatedResult(res *models.GraphQLResponse, collection string, grouped bool) (*generativeQueryResult, error) {
+	get, _ := res.Data["Get"].(map[string]any)
+	objs, _ := get[collection].([]any)
+
+	result := &generativeQueryResult{
+		Generated: make([]string, 0, len(objs)),
+		Objects:   make([]any, 0, len(objs)),
+	}
+	var errs []string
+	for i, obj := range objs {
+		props, ok := obj.(map[string]any)
+		if !ok {
+			continue
+		}
+		additional, _ := props["_additional"].(map[string]any)
+		generate, _ := additional["generate"].(map[string]any)
+		delete(additional, "generate")
+		result.Objects = append(result.Objects, props)
+
+		if msg, _ := generate["error"].(string); msg != "" {
+			errs = append(errs, fmt.Sprintf("object %d: %s", i, msg))
+		}
+		key := "singleResult"
+		if grouped {
+			key = "groupedResult"
+		}
+		if text, _ := generate[key].(string); text != "" {
+			result.Generated = append(result.Generated, text)
+		}
+	}
+	if len(errs) > 0 && len(result.Generated) == 0 {
+		return nil, fmt.Errorf("generate: %s", strings.Join(errs, "\n"))
+	}
+	result.Errors = errs
+
+	return result, nil
+}
+
+// graphQLErrors joins the error messages of the GraphQL response res.
+//
+// Weaviate reports GraphQL errors in the body of a 200 OK response, so they don't surface as request errors.
+func graphQLErrors(res *models.GraphQLResponse) error {
+	var err error
+	for _, e := range res.Errors {
+		if e != nil {
+			err = errors.Join(err, errors.New(e.Message))
+		}
+	}
+
+	return err
+}
+
+type aggregateArgs struct {
+	Collection string   `json:"collection" jsonschema:"collection name"`
+	GroupBy    string   `json:"groupBy,omitempty" jsonschema:"optional property to group the counts by"`
+	Fields     []string `json:"fields,omitempty" jsonschema:"properties to aggregate; numeric properties also return minimum, maximum and mean"`
+}
+
+// Aggregate returns the object count of a collection and aggregates of the given fields.
+func (w *weaviateClient) Aggregate(ctx context.Context, _ *mcp.CallToolRequest, args aggregateArgs) (*mcp.CallToolResult, any, error) {
+	fields := []weaviate_graphql.Field{
+		{
+			Name:   "meta",
+			Fields: []weaviate_graphql.Field{{Name: "count"}},
+		},
+	}
+
+	if len(args.Fields) > 0 {
+		class, err := w.Schema().ClassGetter().WithClassName(args.Collection).Do(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get class %q: %w", args.Collection, responseErrors(err))
+		}
+		dataTypes := make(map[string]string, len(class.Properties))
+		for _, prop := range class.Properties {
+			if len(prop.DataType) > 0 {
+				dataTypes[prop.Name] = prop.DataType[0]
+			}
+		}
+
+		for _, name := range args.Fields {
+			dataType, ok := dataTypes[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown property %q of class %q", name, args.Collection)
+			}
+			aggregates := []weaviate_graphql.Field{{Name: "count"}}
+			switch schema.DataType(dataType) {
+			case schema.DataTypeInt, schema.DataTypeNumber:
+				aggregates = append(aggregates,
+					weaviate_graphql.Field{Name: "minimum"},
+					weaviate_graphql.Field{Name: "maximum"},
+					weaviate_graphql.Field{Name: "mean"},
+				)
+			}
+			fields = append(fields, weaviate_graphql.Field{Name: name, Fields: aggregates})
+		}
+	}
+
+	agg := w.GraphQL().Aggregate().WithClassName(args.Collection)
+	if args.GroupBy != "" {
+		agg = agg.WithGroupBy(args.GroupBy)
+		fields = append(fields, weaviate_graphql.Field{
+			Name: "groupedBy",
+			Fields: []weaviate_graphql.Field{
+				{Name: "path"},
+				{Name: "value"},
+			},
+		})
+	}
+
+	res, err := agg.WithFields(fields...).Do(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := graphQLErrors(res); err != nil {
+		return nil, nil, fmt.Errorf("aggregate: %w", err)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal aggregate response: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(b),
+			},
+		},
+	}, nil, nil
+}
+
+type getObjectArgs struct {
+	Collection    string `json:"collection" jsonschema:"collection name"`
+	ID            string `json:"id" jsonschema:"object UUID"`
+	IncludeVector bool   `json:"includeVector,omitempty" jsonschema:"if true, also return the object vector"`
+	Tenant        string `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
+}
+
+// GetObject gets a single object by its ID.
+func (w *weaviateClient) GetObject(ctx context.Context, _ *mcp.CallToolRequest, args getObjectArgs) (*mcp.CallToolResult, any, error) {
+	getter := w.Data().ObjectsGetter().
+		WithClassName(args.Collection).
+		WithID(args.ID)
+	if args.IncludeVector {
+		getter = getter.WithVector()
+	}
+	if args.Tenant != "" {
+		getter = getter.WithTenant(args.Tenant)
+	}
+
+	objs, err := getter.Do(ctx)
+	if err != nil {
+		var clientErr *fault.WeaviateClientError
+		if errors.As(err, &clientErr) && clientErr.StatusCode == http.StatusNotFound {
+			return nil, nil, fmt.Errorf("object %s not found in %q", args.ID, args.Collection)
+		}
+		return nil, nil, fmt.Errorf("get object: %w", responseErrors(err))
+	}
+	b, err := json.Marshal(objs[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal object: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(b),
+			},
+		},
+	}, nil, nil
+}
+
+type updateObjectArgs struct {
+	Collection string `json:"collection" jsonschema:"collection name"`
+	ID         string `json:"id" jsonschema:"object UUID"`
+	Properties any    `json:"properties" jsonschema:"object properties"`
+	Merge      bool   `json:"merge,omitempty" jsonschema:"if true, merge the properties into the object instead of replacing the whole object"`
+	Tenant     string `json:"tenant,omitempty" jsonschema:"tenant of a multi-tenant collection; must be omitted for collections without multi-tenancy"`
+}
+
+// UpdateObject updates an object, either replacing it or merging the given properties into it.
+func (w *weaviateClient) UpdateObject(ctx context.Context, _ *mcp.CallToolRequest, args updateObjectArgs) (*mcp.CallToolResult, any, error) {
+	updater := w.Data().Updater().
+		WithClassName(args.Collection).
+		WithID(args.ID).
+		WithProperties(args.Properties)
+	if args.Merge {
+		updater = updater.WithMerge()
+	}
+	if args.Tenant != "" {
+		updater = updater.WithTenant(args.Tenant)
+	}
+
+	if err := updater.Do(ctx); err != nil {
+		return nil, nil, fmt.Errorf("update object: %w", responseErrors(err))
+	}
+
+	action := "replaced"
+	if args.Merge {
+		action = "merged"
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("%s %q object %s", action, args.Collection, args.ID),
+			},
+		},
+	}, nil, nil
+}
+
+// responseErrors joins the error messages of a Weaviate error response body carried by err.
+//
+// It returns err as is if err isn't an unexpected status code error or its body isn't an error response.
+func responseErrors(err error) error {
+	var clientErr *fault.WeaviateClientError
+	if !errors.As(err, &clientErr) || !clientErr.IsUnexpectedStatusCode {
+		return err
+	}
+
+	var resp models.ErrorResponse
+	if json.Unmarshal([]byte(clientErr.Msg), &resp) != nil || len(resp.Error) == 0 {
+		return err
+	}
+
+	var joined error
+	for _, e := range resp.Error {
+		if e != nil {
+			joined = errors.Join(joined, errors.New(e.Message))
+		}
+	}
+	if joined == nil {
+		return err
+	}
+
+	return fmt.Errorf("status code %d: %w", clientErr.StatusCode, joined)
+}
+
 func (w *weaviateClient) batchInsert(ctx context.Context, objs ...*models.Object) ([]models.ObjectsGetResponse, error) {
 	resp, err := w.Batch().ObjectsBatcher().WithObjects(objs...).Do(ctx)
 	if err != nil {
@@ -310,12 +1393,24 @@ func (w *weaviateClient) batchInsert(ctx context.Context, objs ...*models.Object
 	}
 
 	for _, res := range resp {
-		if res.Result != nil && res.Result.Errors != nil && res.Result.Errors.Error != nil {
-			for _, nestedErr := range res.Result.Errors.Error {
-				err = errors.Join(err, errors.New(nestedErr.Message))
-			}
+		for _, msg := range objectErrors(res) {
+			err = errors.Join(err, errors.New(msg))
 		}
 	}
 
 	return resp, err
 }
+
+// objectErrors returns the error messages of a single object in a batch response.
+func objectErrors(res models.ObjectsGetResponse) []string {
+	if res.Result == nil || res.Result.Errors == nil {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(res.Result.Errors.Error))
+	for _, nestedErr := range res.Result.Errors.Error {
+		msgs = append(msgs, nestedErr.Message)
+	}
+
+	return msgs
+}
@@ -0,0 +1,406 @@
+// Copyright 2025 The mcp-servers Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	json "encoding/json/v2"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// vectorizedClass returns a class with the given properties and a single vector whose vectorizer embeds sourceProperties.
+func vectorizedClass(properties []string, sourceProperties any) *models.Class {
+	class := &models.Class{
+		Class: "Snippet",
+		VectorConfig: map[string]models.VectorConfig{
+			"default": {
+				VectorIndexType: "hnsw",
+				Vectorizer: map[string]any{
+					"text2vec-huggingface": map[string]any{
+						"sourceProperties": sourceProperties,
+					},
+				},
+			},
+		},
+	}
+	for _, name := range properties {
+		class.Properties = append(class.Properties, &models.Property{
+			Name:     name,
+			DataType: []string{"text"},
+		})
+	}
+
+	return class
+}
+
+func TestValidateClass(t *testing.T) {
+	tests := map[string]struct {
+		class   *models.Class
+		wantErr string
+	}{
+		"go preset": {
+			class: goClass(),
+		},
+		"no vectorizer": {
+			class: &models.Class{
+				Class:      "Snippet",
+				Properties: []*models.Property{{Name: "code", DataType: []string{"text"}}},
+			},
+		},
+		"string source properties": {
+			class: vectorizedClass([]string{"title", "code"}, []string{"title", "code"}),
+		},
+		"decoded JSON source properties": {
+			class: vectorizedClass([]string{"title", "code"}, []any{"title", "code"}),
+		},
+		"unknown source property": {
+			class:   vectorizedClass([]string{"title"}, []string{"title", "code"}),
+			wantErr: `vector "default": text2vec-huggingface source property "code" is not a property of class "Snippet"`,
+		},
+		"unknown decoded JSON source property": {
+			class:   vectorizedClass(nil, []any{"code"}),
+			wantErr: `vector "default": text2vec-huggingface source property "code" is not a property of class "Snippet"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateClass(tt.class)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateClass: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("validateClass: got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateSchemaClassDryRun(t *testing.T) {
+	// A dry run must not reach the cluster, so the client has no connection to use
+	w := &weaviateClient{}
+
+	res, _, err := w.CreateSchemaClass(t.Context(), nil, createSchemaClassArgs{
+		ClassName: "Snippet",
+		Properties: []propertySpec{
+			{Name: "code", DataType: "text"},
+			{Name: "lines", DataType: "int"},
+		},
+		Vectorizer: "text2vec-huggingface",
+		Model:      "sentence-transformers/all-MiniLM-L6-v2",
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateSchemaClass: %v", err)
+	}
+	if len(res.Content) != 1 {
+		t.Fatalf("CreateSchemaClass: got %d contents, want 1", len(res.Content))
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("CreateSchemaClass: got %T content, want *mcp.TextContent", res.Content[0])
+	}
+
+	var class models.Class
+	if err := json.Unmarshal([]byte(text.Text), &class); err != nil {
+		t.Fatalf("unmarshal dry run class: %v", err)
+	}
+	if class.Class != "Snippet" {
+		t.Errorf("class name = %q, want %q", class.Class, "Snippet")
+	}
+	var names []string
+	for _, prop := range class.Properties {
+		names = append(names, prop.Name)
+	}
+	if got, want := strings.Join(names, ","), "code,lines"; got != want {
+		t.Errorf("properties = %s, want %s", got, want)
+	}
+	if _, ok := class.VectorConfig["default"].Vectorizer.(map[string]any)["text2vec-huggingface"]; !ok {
+		t.Errorf("vectorizer = %v, want text2vec-huggingface", class.VectorConfig["default"].Vectorizer)
+	}
+
+	// An invalid class is rejected before the dry run returns its definition
+	_, _, err = w.CreateSchemaClass(t.Context(), nil, createSchemaClassArgs{
+		ClassName:  "Snippet",
+		Properties: []propertySpec{{Name: "code", DataType: "string[][]"}},
+		DryRun:     true,
+	})
+	if want := `build schema class: property "code": unknown data type "string[][]"`; err == nil || err.Error() != want {
+		t.Errorf("CreateSchemaClass with an unknown data type: got error %v, want %q", err, want)
+	}
+}
+
+func TestCreateSchemaClassVectors(t *testing.T) {
+	properties := []propertySpec{
+		{Name: "title", DataType: "text"},
+		{Name: "code", DataType: "text"},
+	}
+	tests := map[string]struct {
+		args    createSchemaClassArgs
+		wantErr string
+	}{
+		"declared source properties": {
+			args: createSchemaClassArgs{
+				Vectors: []vectorSpec{
+					{Name: "title", Vectorizer: "text2vec-huggingface", SourceProperties: []string{"title"}},
+					{Name: "code", Vectorizer: "text2vec-huggingface", Model: "microsoft/codebert-base", SourceProperties: []string{"title", "code"}},
+				},
+			},
+		},
+		"undeclared source property": {
+			args: createSchemaClassArgs{
+				Vectors: []vectorSpec{
+					{Name: "title", Vectorizer: "text2vec-huggingface", SourceProperties: []string{"title"}},
+					{Name: "body", Vectorizer: "text2vec-huggingface", SourceProperties: []string{"body"}},
+				},
+			},
+			wantErr: `validate schema class: vector "body": text2vec-huggingface source property "body" is not a property of class "Snippet"`,
+		},
+		"vectorizer and vectors": {
+			args: createSchemaClassArgs{
+				Vectorizer: "text2vec-huggingface",
+				Vectors:    []vectorSpec{{Name: "title", Vectorizer: "text2vec-huggingface"}},
+			},
+			wantErr: "build schema class: vectorizer and vectors can't be used together: name the vector in vectors instead",
+		},
+		"unnamed vector": {
+			args: createSchemaClassArgs{
+				Vectors: []vectorSpec{{Vectorizer: "text2vec-huggingface"}},
+			},
+			wantErr: "build schema class: vector 0: name and vectorizer are required",
+		},
+		"duplicate vector": {
+			args: createSchemaClassArgs{
+				Vectors: []vectorSpec{
+					{Name: "title", Vectorizer: "text2vec-huggingface"},
+					{Name: "title", Vectorizer: "text2vec-openai"},
+				},
+			},
+			wantErr: `build schema class: vector "title": duplicate name`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Invalid classes must be rejected before reaching the cluster, so only valid ones are dry runs
+			w := &weaviateClient{}
+			args := tt.args
+			args.ClassName = "Snippet"
+			args.Properties = properties
+			args.DryRun = tt.wantErr == ""
+
+			res, _, err := w.CreateSchemaClass(t.Context(), nil, args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CreateSchemaClass: got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSchemaClass: %v", err)
+			}
+
+			var class models.Class
+			if err := json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &class); err != nil {
+				t.Fatalf("unmarshal dry run class: %v", err)
+			}
+			for _, vector := range args.Vectors {
+				config, _ := class.VectorConfig[vector.Name].Vectorizer.(map[string]any)[vector.Vectorizer].(map[string]any)
+				if got := sourceProperties(config["sourceProperties"]); !slices.Equal(got, vector.SourceProperties) {
+					t.Errorf("vector %q source properties = %v, want %v", vector.Name, got, vector.SourceProperties)
+				}
+			}
+		})
+	}
+}
+
+// fakeGraphQL returns a client of a fake cluster that answers every GraphQL request with response
+// and records the query of the last request in query. The cluster has the generative-openai module enabled.
+func fakeGraphQL(t *testing.T, response string, query *string) *weaviateClient {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/meta" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"version":"1.32.0","modules":{"generative-openai":{}}}`)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		b, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(b, &body)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if query != nil {
+			*query = body.Query
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(ts.Close)
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := weaviate.NewClient(weaviate.Config{
+		Host:   u.Host,
+		Scheme: u.Scheme,
+	})
+	if err != nil {
+		t.Fatalf("weaviate.NewClient: %v", err)
+	}
+
+	return &weaviateClient{
+		Client:        client,
+		maxQueryLimit: defaultMaxQueryLimit,
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	tests := map[string]struct {
+		res     *models.GraphQLResponse
+		wantErr error
+	}{
+		"NoErrors": {
+			res: &models.GraphQLResponse{},
+		},
+		"NilError": {
+			res: &models.GraphQLResponse{Errors: []*models.GraphQLError{nil}},
+		},
+		"Errors": {
+			res: &models.GraphQLResponse{Errors: []*models.GraphQLError{
+				{Message: `Cannot query field "titel" on type "Snippet".`},
+				nil,
+				{Message: `Cannot query field "cod" on type "Snippet".`},
+			}},
+			wantErr: errors.Join(
+				errors.New(`Cannot query field "titel" on type "Snippet".`),
+				errors.New(`Cannot query field "cod" on type "Snippet".`),
+			),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := graphQLErrors(tt.res)
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("graphQLErrors: got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryBadField(t *testing.T) {
+	// Weaviate reports an unknown field in the errors of a 200 response, with no data
+	const response = `{"errors":[{"locations":[{"column":6,"line":1}],"message":"Cannot query field \"titel\" on type \"Snippet\". Did you mean \"title\"?","path":null}]}`
+	var query string
+	w := fakeGraphQL(t, response, &query)
+
+	res, _, err := w.Query(t.Context(), nil, queryArgs{
+		Collection:       "Snippet",
+		Query:            "channels",
+		TargetProperties: []string{"titel"},
+	})
+	if want := `query: Cannot query field "titel" on type "Snippet". Did you mean "title"?`; err == nil || err.Error() != want {
+		t.Fatalf("Query: got result %v and error %v, want error %q", res, err, want)
+	}
+	if !strings.Contains(query, "titel") {
+		t.Errorf("GraphQL query %q doesn't request the titel field", query)
+	}
+}
+
+func TestGenerativeQueryErrors(t *testing.T) {
+	tests := map[string]struct {
+		objects string
+		want    generativeQueryResult
+		wantErr string
+	}{
+		"SomeFailed": {
+			objects: `[
+				{"title": "a", "_additional": {"id": "1", "generate": {"singleResult": "summary of a", "error": null}}},
+				{"title": "b", "_additional": {"id": "2", "generate": {"singleResult": null, "error": "rate limited"}}},
+				{"title": "c", "_additional": {"id": "3", "generate": {"singleResult": "summary of c", "error": null}}}
+			]`,
+			want: generativeQueryResult{
+				Generated: []string{"summary of a", "summary of c"},
+				Objects: []any{
+					map[string]any{"title": "a", "_additional": map[string]any{"id": "1"}},
+					map[string]any{"title": "b", "_additional": map[string]any{"id": "2"}},
+					map[string]any{"title": "c", "_additional": map[string]any{"id": "3"}},
+				},
+				Errors: []string{"object 1: rate limited"},
+			},
+		},
+		"AllFailed": {
+			objects: `[
+				{"title": "a", "_additional": {"id": "1", "generate": {"singleResult": null, "error": "rate limited"}}},
+				{"title": "b", "_additional": {"id": "2", "generate": {"singleResult": null, "error": "context too long"}}}
+			]`,
+			wantErr: "generate: object 0: rate limited\nobject 1: context too long",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := fakeGraphQL(t, `{"data":{"Get":{"Snippet":`+tt.objects+`}}}`, nil)
+
+			res, _, err := w.GenerativeQuery(t.Context(), nil, generativeQueryArgs{
+				Collection:       "Snippet",
+				Query:            "channels",
+				Prompt:           "Summarize {title}",
+				TargetProperties: []string{"title"},
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("GenerativeQuery: got result %v and error %v, want error %q", res, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerativeQuery: %v", err)
+			}
+
+			var got generativeQueryResult
+			if err := json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &got); err != nil {
+				t.Fatalf("unmarshal generative query result: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GenerativeQuery result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}